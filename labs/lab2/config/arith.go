@@ -0,0 +1,311 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ArithOperators es la tabla de operadores de las expresiones aritméticas:
+// + - * / % con asociatividad izquierda, ^ con asociatividad derecha, menos
+// unario y factorial postfijo. -2^2 es -(2^2), como en matemática.
+var ArithOperators = OperatorTable{
+	Infix: map[string]Operator{
+		"+": {Symbol: "+", Kind: InfixOp, Prec: 1, Assoc: LeftAssoc},
+		"-": {Symbol: "-", Kind: InfixOp, Prec: 1, Assoc: LeftAssoc},
+		"*": {Symbol: "*", Kind: InfixOp, Prec: 2, Assoc: LeftAssoc},
+		"/": {Symbol: "/", Kind: InfixOp, Prec: 2, Assoc: LeftAssoc},
+		"%": {Symbol: "%", Kind: InfixOp, Prec: 2, Assoc: LeftAssoc},
+		"^": {Symbol: "^", Kind: InfixOp, Prec: 4, Assoc: RightAssoc},
+	},
+	Prefix: map[string]Operator{
+		"-": {Symbol: "-", Name: "neg", Kind: PrefixOp, Prec: 3},
+		"+": {Symbol: "+", Name: "pos", Kind: PrefixOp, Prec: 3},
+	},
+	Postfix: map[string]Operator{
+		"!": {Symbol: "!", Kind: PostfixOp, Prec: 5},
+	},
+	Functions: map[string]int{
+		"max": VariadicArity, "min": VariadicArity,
+		"abs": 1, "sqrt": 1, "exp": 1, "ln": 1, "sin": 1, "cos": 1, "tan": 1,
+		"pow": 2, "log": 2,
+	},
+}
+
+// ArithConstants son las variables predefinidas del evaluador.
+var ArithConstants = map[string]float64{"pi": math.Pi, "e": math.E}
+
+// TokenizeArith separa una expresión aritmética en tokens. Los números
+// pueden tener parte decimal y exponente (1.5e3); los identificadores son
+// letras, dígitos y '_'.
+func TokenizeArith(expr string) ([]Token, error) {
+	runes := []rune(expr)
+	var toks []Token
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case unicode.IsDigit(c) || c == '.':
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				j := i + 1
+				if j < len(runes) && (runes[j] == '+' || runes[j] == '-') {
+					j++
+				}
+				if j < len(runes) && unicode.IsDigit(runes[j]) {
+					for i = j; i < len(runes) && unicode.IsDigit(runes[i]); i++ {
+					}
+				}
+			}
+			text := string(runes[start:i])
+			if _, err := strconv.ParseFloat(text, 64); err != nil {
+				return nil, &SyntaxError{start, fmt.Sprintf("número inválido %q", text)}
+			}
+			toks = append(toks, Token{Kind: NumberToken, Text: text, Pos: start})
+			continue
+		case unicode.IsLetter(c) || c == '_':
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			toks = append(toks, Token{Kind: IdentToken, Text: string(runes[start:i]), Pos: start})
+			continue
+		case c == '(':
+			toks = append(toks, Token{Kind: LParenToken, Text: "(", Pos: i})
+		case c == ')':
+			toks = append(toks, Token{Kind: RParenToken, Text: ")", Pos: i})
+		case c == ',':
+			toks = append(toks, Token{Kind: CommaToken, Text: ",", Pos: i})
+		case strings.ContainsRune("+-*/%^!", c):
+			toks = append(toks, Token{Kind: OpToken, Text: string(c), Pos: i})
+		default:
+			return nil, &SyntaxError{i, fmt.Sprintf("carácter inesperado %q", c)}
+		}
+		i++
+	}
+	return toks, nil
+}
+
+// ExprNode es un nodo del árbol de una expresión aritmética: un operando
+// (Args vacío), un operador o una llamada a función.
+type ExprNode struct {
+	Item Item
+	Args []*ExprNode
+}
+
+// BuildExprTree arma el árbol a partir de la expresión en postfix.
+func BuildExprTree(items []Item) (*ExprNode, error) {
+	var stack Stack[*ExprNode]
+	for _, it := range items {
+		n := 0
+		switch it.Kind {
+		case OperatorItem:
+			n = 2
+			if it.Op.Kind != InfixOp {
+				n = 1
+			}
+		case CallItem:
+			n = it.Args
+		}
+		if stack.Len() < n {
+			return nil, fmt.Errorf("postfix inválido: %s necesita %d operandos", it, n)
+		}
+		node := &ExprNode{Item: it, Args: make([]*ExprNode, n)}
+		for k := n - 1; k >= 0; k-- {
+			node.Args[k], _ = stack.Pop()
+		}
+		stack.Push(node)
+	}
+	if stack.Len() != 1 {
+		return nil, errors.New("postfix inválido: la pila final no tiene un único árbol")
+	}
+	root, _ := stack.Pop()
+	return root, nil
+}
+
+// label es el texto de un nodo en el árbol.
+func (n *ExprNode) label() string {
+	switch n.Item.Kind {
+	case OperatorItem:
+		return n.Item.Op.PostfixName()
+	default:
+		return n.Item.Text
+	}
+}
+
+// TreeString dibuja el árbol con ramas, un nodo por línea.
+func (n *ExprNode) TreeString() string {
+	var b strings.Builder
+	b.WriteString(n.label() + "\n")
+	var walk func(n *ExprNode, prefix string)
+	walk = func(n *ExprNode, prefix string) {
+		for i, c := range n.Args {
+			branch, next := "├── ", "│   "
+			if i == len(n.Args)-1 {
+				branch, next = "└── ", "    "
+			}
+			b.WriteString(prefix + branch + c.label() + "\n")
+			walk(c, prefix+next)
+		}
+	}
+	walk(n, "")
+	return b.String()
+}
+
+// Eval evalúa el árbol. Las variables se buscan en vars y luego en
+// ArithConstants.
+func (n *ExprNode) Eval(vars map[string]float64) (float64, error) {
+	args := make([]float64, len(n.Args))
+	for i, c := range n.Args {
+		v, err := c.Eval(vars)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = v
+	}
+
+	it := n.Item
+	switch it.Kind {
+	case OperandItem:
+		if it.Number {
+			return strconv.ParseFloat(it.Text, 64)
+		}
+		if v, ok := vars[it.Text]; ok {
+			return v, nil
+		}
+		if v, ok := ArithConstants[it.Text]; ok {
+			return v, nil
+		}
+		return 0, &SyntaxError{it.Pos, fmt.Sprintf("variable %q sin valor", it.Text)}
+	case OperatorItem:
+		return applyOperator(it, args)
+	default:
+		return applyFunction(it, args)
+	}
+}
+
+// applyOperator aplica un operador de ArithOperators.
+func applyOperator(it Item, a []float64) (float64, error) {
+	switch it.Op.PostfixName() {
+	case "neg":
+		return -a[0], nil
+	case "pos":
+		return a[0], nil
+	case "!":
+		if a[0] < 0 || a[0] != math.Trunc(a[0]) {
+			return 0, &SyntaxError{it.Pos, fmt.Sprintf("factorial de %v, se espera un entero no negativo", a[0])}
+		}
+		return math.Gamma(a[0] + 1), nil
+	case "+":
+		return a[0] + a[1], nil
+	case "-":
+		return a[0] - a[1], nil
+	case "*":
+		return a[0] * a[1], nil
+	case "/":
+		if a[1] == 0 {
+			return 0, &SyntaxError{it.Pos, "división entre cero"}
+		}
+		return a[0] / a[1], nil
+	case "%":
+		if a[1] == 0 {
+			return 0, &SyntaxError{it.Pos, "módulo entre cero"}
+		}
+		return math.Mod(a[0], a[1]), nil
+	case "^":
+		return math.Pow(a[0], a[1]), nil
+	}
+	return 0, &SyntaxError{it.Pos, fmt.Sprintf("operador sin evaluación %q", it.Op.Symbol)}
+}
+
+// applyFunction aplica una función de ArithOperators.
+func applyFunction(it Item, a []float64) (float64, error) {
+	switch it.Text {
+	case "max":
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Max(m, v)
+		}
+		return m, nil
+	case "min":
+		m := a[0]
+		for _, v := range a[1:] {
+			m = math.Min(m, v)
+		}
+		return m, nil
+	case "abs":
+		return math.Abs(a[0]), nil
+	case "sqrt":
+		if a[0] < 0 {
+			return 0, &SyntaxError{it.Pos, "raíz cuadrada de un número negativo"}
+		}
+		return math.Sqrt(a[0]), nil
+	case "exp":
+		return math.Exp(a[0]), nil
+	case "ln":
+		return math.Log(a[0]), nil
+	case "log": // log(base, x)
+		return math.Log(a[1]) / math.Log(a[0]), nil
+	case "sin":
+		return math.Sin(a[0]), nil
+	case "cos":
+		return math.Cos(a[0]), nil
+	case "tan":
+		return math.Tan(a[0]), nil
+	case "pow":
+		return math.Pow(a[0], a[1]), nil
+	}
+	return 0, &SyntaxError{it.Pos, fmt.Sprintf("función sin evaluación %q", it.Text)}
+}
+
+// CompileArith convierte una expresión aritmética en su postfix y su árbol.
+func CompileArith(expr string) ([]Item, *ExprNode, error) {
+	toks, err := TokenizeArith(expr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(toks) == 0 {
+		return nil, nil, errors.New("expresión vacía")
+	}
+	items, err := ArithOperators.ToPostfix(toks)
+	if err != nil {
+		return nil, nil, err
+	}
+	tree, err := BuildExprTree(items)
+	if err != nil {
+		return nil, nil, err
+	}
+	return items, tree, nil
+}
+
+// SplitAssignment separa una línea "x = expr" en la variable y la
+// expresión; offset es la posición (en runas) donde empieza expr dentro de
+// line. Si la línea no es una asignación, name es "" y expr es line.
+func SplitAssignment(line string) (name, expr string, offset int) {
+	left, right, ok := strings.Cut(line, "=")
+	if !ok {
+		return "", line, 0
+	}
+	name = strings.TrimSpace(left)
+	if name == "" {
+		return "", line, 0
+	}
+	for i, r := range name {
+		if !(unicode.IsLetter(r) || r == '_' || (i > 0 && unicode.IsDigit(r))) {
+			return "", line, 0
+		}
+	}
+	return name, right, len([]rune(left)) + 1
+}
+
+// FormatNumber escribe un valor sin ceros de más (3 en lugar de 3.000000).
+func FormatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
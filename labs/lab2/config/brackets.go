@@ -0,0 +1,386 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Position ubica un delimitador en el texto revisado. Offset cuenta runas
+// desde 0; Line y Column empiezan en 1.
+type Position struct {
+	Offset, Line, Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// BracketErrorKind clasifica los errores de balanceo.
+type BracketErrorKind int
+
+const (
+	Unclosed   BracketErrorKind = iota // apertura que nunca se cierra
+	Unopened                           // cierre sin apertura
+	Mismatched                         // otro delimitador donde va el cierre de una apertura
+)
+
+// RepairOp es la edición que propone una reparación.
+type RepairOp int
+
+const (
+	Insert RepairOp = iota
+	Delete
+	Replace
+)
+
+// Repair es una edición de un solo delimitador. Para Insert, Pos es donde se
+// inserta New; para Delete y Replace, Pos es el delimitador Old.
+type Repair struct {
+	Op       RepairOp
+	Pos      Position
+	Old, New string
+}
+
+func (r Repair) String() string {
+	switch r.Op {
+	case Insert:
+		return fmt.Sprintf("insertar %s en %s", r.New, r.Pos)
+	case Delete:
+		return fmt.Sprintf("borrar %s en %s", r.Old, r.Pos)
+	default:
+		return fmt.Sprintf("reemplazar %s por %s en %s", r.Old, r.New, r.Pos)
+	}
+}
+
+// BracketError es un delimitador mal balanceado junto con su reparación.
+// Open y OpenPos son la apertura que un cierre Mismatched intenta cerrar.
+type BracketError struct {
+	Kind    BracketErrorKind
+	Delim   string
+	Pos     Position
+	Open    string
+	OpenPos Position
+	Repair  Repair
+}
+
+func (e BracketError) Error() string {
+	switch e.Kind {
+	case Unclosed:
+		return fmt.Sprintf("%s: %s no se cierra", e.Pos, e.Delim)
+	case Unopened:
+		return fmt.Sprintf("%s: %s no tiene apertura", e.Pos, e.Delim)
+	default:
+		return fmt.Sprintf("%s: se encontró %s pero %s de %s se cierra con otro delimitador", e.Pos, e.Delim, e.Open, e.OpenPos)
+	}
+}
+
+// BracketChecker revisa que los delimitadores de un texto estén balanceados
+// (lenguaje de Dyck). Los pares pueden ser símbolos como "(" y ")" o palabras
+// como "begin" y "end"; las palabras solo cuentan como delimitador si no
+// forman parte de otra palabra.
+type BracketChecker struct {
+	closeToOpen map[string]string
+	openToClose map[string]string
+	delims      []string // de más largo a más corto
+
+	// Trace, si no es nil, se instala en la pila de aperturas.
+	Trace func(op string, delim string)
+}
+
+// NewBracketChecker crea un verificador con los pares dados, en el formato de
+// Pairs (cierre → apertura). NewBracketChecker(Pairs) usa los del ejercicio 2.
+func NewBracketChecker(pairs ...map[string]string) *BracketChecker {
+	c := &BracketChecker{closeToOpen: make(map[string]string), openToClose: make(map[string]string)}
+	for _, m := range pairs {
+		for cl, op := range m {
+			c.closeToOpen[cl] = op
+			c.openToClose[op] = cl
+		}
+	}
+	for d := range c.closeToOpen {
+		c.delims = append(c.delims, d)
+	}
+	for d := range c.openToClose {
+		c.delims = append(c.delims, d)
+	}
+	sort.Slice(c.delims, func(i, j int) bool {
+		if len(c.delims[i]) != len(c.delims[j]) {
+			return len(c.delims[i]) > len(c.delims[j])
+		}
+		return c.delims[i] < c.delims[j]
+	})
+	return c
+}
+
+// ParsePairs lee pares definidos por el usuario como "begin:end,if:fi" y los
+// devuelve en el formato de Pairs. Cada delimitador pertenece a un solo par:
+// es un error reutilizar una apertura o un cierre con otra pareja, o usar
+// como apertura lo que otro par usa como cierre, tanto dentro de spec como
+// respecto de los pares de base (por ejemplo Pairs). Repetir un par igual no
+// es un error.
+func ParsePairs(spec string, base ...map[string]string) (map[string]string, error) {
+	closeToOpen := make(map[string]string)
+	openToClose := make(map[string]string)
+	add := func(op, cl string) error {
+		if prev, ok := openToClose[op]; ok && prev != cl {
+			return fmt.Errorf("la apertura %s ya se cierra con %s, no con %s", op, prev, cl)
+		}
+		if prev, ok := closeToOpen[cl]; ok && prev != op {
+			return fmt.Errorf("el cierre %s ya cierra %s, no %s", cl, prev, op)
+		}
+		if _, ok := closeToOpen[op]; ok {
+			return fmt.Errorf("%s ya es un cierre, no puede ser apertura", op)
+		}
+		if _, ok := openToClose[cl]; ok {
+			return fmt.Errorf("%s ya es una apertura, no puede ser cierre", cl)
+		}
+		closeToOpen[cl] = op
+		openToClose[op] = cl
+		return nil
+	}
+	for _, m := range base {
+		for cl, op := range m {
+			if err := add(op, cl); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	pairs := make(map[string]string)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		op, cl, ok := strings.Cut(item, ":")
+		if !ok || op == "" || cl == "" || op == cl {
+			return nil, fmt.Errorf("par inválido %q, se espera apertura:cierre", item)
+		}
+		if err := add(op, cl); err != nil {
+			return nil, fmt.Errorf("par inválido %q: %v", item, err)
+		}
+		pairs[cl] = op
+	}
+	return pairs, nil
+}
+
+// delimToken es un delimitador encontrado en el texto.
+type delimToken struct {
+	text string
+	open bool
+	pos  Position
+}
+
+// isWordRune indica si r forma parte de una palabra.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// tokens devuelve los delimitadores de s en orden, junto con la posición
+// del final del texto.
+func (c *BracketChecker) tokens(s string) ([]delimToken, Position) {
+	runes := []rune(s)
+	var toks []delimToken
+	pos := Position{Line: 1, Column: 1}
+	advance := func(n int) {
+		for k := 0; k < n; k++ {
+			if runes[pos.Offset] == '\n' {
+				pos.Line++
+				pos.Column = 0
+			}
+			pos.Offset++
+			pos.Column++
+		}
+	}
+
+	for pos.Offset < len(runes) {
+		matched := ""
+		for _, d := range c.delims {
+			dr := []rune(d)
+			end := pos.Offset + len(dr)
+			if end > len(runes) || string(runes[pos.Offset:end]) != d {
+				continue
+			}
+			// "end" no es delimitador dentro de "endless" ni de "blend"
+			if isWordRune(dr[0]) && pos.Offset > 0 && isWordRune(runes[pos.Offset-1]) {
+				continue
+			}
+			if isWordRune(dr[len(dr)-1]) && end < len(runes) && isWordRune(runes[end]) {
+				continue
+			}
+			matched = d
+			break
+		}
+		if matched == "" {
+			advance(1)
+			continue
+		}
+		_, isOpen := c.openToClose[matched]
+		toks = append(toks, delimToken{text: matched, open: isOpen, pos: pos})
+		advance(len([]rune(matched)))
+	}
+	return toks, pos
+}
+
+// Check revisa s y devuelve sus errores en orden de aparición; nil si está
+// balanceado. Las reparaciones de todos los errores juntas son una cantidad
+// mínima de ediciones (insertar, borrar o reemplazar un delimitador) que
+// balancea s; ApplyRepairs las aplica. Si s no está balanceado, el costo es
+// cúbico en la cantidad de delimitadores.
+func (c *BracketChecker) Check(s string) []BracketError {
+	toks, end := c.tokens(s)
+
+	// recorrido con pila: si todo cierra bien no hace falta reparar nada
+	stack := Stack[delimToken]{}
+	if c.Trace != nil {
+		stack.Trace = func(op string, t delimToken) { c.Trace(op, t.text) }
+	}
+	balanced := true
+	for _, t := range toks {
+		if t.open {
+			stack.Push(t)
+			continue
+		}
+		top, ok := stack.Peek()
+		if !ok || c.openToClose[top.text] != t.text {
+			balanced = false
+			break
+		}
+		stack.Pop()
+	}
+	if balanced && stack.IsEmpty() {
+		return nil
+	}
+	return c.minimalRepair(toks, end)
+}
+
+// minimalRepair calcula con programación dinámica sobre intervalos la menor
+// cantidad de ediciones que balancea toks. cost[i][j] es el costo de
+// toks[i:j]: o toks[i] queda sin pareja (se borra o se le inserta la pareja,
+// costo 1), o toks[i] se empareja con un toks[k] posterior. Si toks[i] es
+// una apertura, toks[k] cuesta 0 si es su cierre y 1 si hay que reemplazarlo
+// por él (aunque sea otra apertura); si toks[i] es un cierre, solo conviene
+// emparejarlo con otro cierre, reemplazando toks[i] por la apertura de
+// toks[k] (costo 1). Cambiar un cierre y una apertura cuesta 2, lo mismo que
+// dejarlos sin pareja, así que ese caso no se considera.
+func (c *BracketChecker) minimalRepair(toks []delimToken, end Position) []BracketError {
+	n := len(toks)
+	cost := make([][]int, n+1)
+	pick := make([][]int, n+1) // -1: toks[i] sin pareja; k: pareja de toks[i]
+	for i := range cost {
+		cost[i] = make([]int, n+1)
+		pick[i] = make([]int, n+1)
+	}
+	for length := 1; length <= n; length++ {
+		for i := 0; i+length <= n; i++ {
+			j := i + length
+			best, choice := cost[i+1][j]+1, -1
+			for k := i + 1; k < j; k++ {
+				pc := cost[i+1][k] + cost[k+1][j]
+				switch {
+				case toks[i].open && c.openToClose[toks[i].text] == toks[k].text:
+				case toks[i].open || !toks[k].open:
+					pc++
+				default:
+					continue
+				}
+				if pc < best {
+					best, choice = pc, k
+				}
+			}
+			cost[i][j], pick[i][j] = best, choice
+		}
+	}
+
+	// reconstrucción: cada intervalo recuerda dónde insertar el cierre de
+	// una apertura sin pareja (antes del cierre que lo rodea o al final)
+	type segment struct {
+		i, j   int
+		before Position
+	}
+	var errs []BracketError
+	todo := Stack[segment]{}
+	todo.Push(segment{0, n, end})
+	for !todo.IsEmpty() {
+		seg, _ := todo.Pop()
+		if seg.i >= seg.j {
+			continue
+		}
+		t := toks[seg.i]
+		k := pick[seg.i][seg.j]
+		switch {
+		case k < 0 && t.open:
+			cl := c.openToClose[t.text]
+			errs = append(errs, BracketError{Kind: Unclosed, Delim: t.text, Pos: t.pos,
+				Repair: Repair{Op: Insert, Pos: seg.before, New: cl}})
+			todo.Push(segment{seg.i + 1, seg.j, seg.before})
+		case k < 0:
+			errs = append(errs, BracketError{Kind: Unopened, Delim: t.text, Pos: t.pos,
+				Repair: Repair{Op: Delete, Pos: t.pos, Old: t.text}})
+			todo.Push(segment{seg.i + 1, seg.j, seg.before})
+		case !t.open:
+			// dos cierres: el primero se reemplaza por la apertura del segundo
+			cl := toks[k]
+			op := c.closeToOpen[cl.text]
+			errs = append(errs, BracketError{Kind: Unopened, Delim: t.text, Pos: t.pos,
+				Repair: Repair{Op: Replace, Pos: t.pos, Old: t.text, New: op}})
+			todo.Push(segment{k + 1, seg.j, seg.before})
+			todo.Push(segment{seg.i + 1, k, cl.pos})
+		default:
+			cl := toks[k]
+			if want := c.openToClose[t.text]; want != cl.text {
+				errs = append(errs, BracketError{Kind: Mismatched, Delim: cl.text, Pos: cl.pos,
+					Open: t.text, OpenPos: t.pos, Repair: Repair{Op: Replace, Pos: cl.pos, Old: cl.text, New: want}})
+			}
+			todo.Push(segment{k + 1, seg.j, seg.before})
+			todo.Push(segment{seg.i + 1, k, cl.pos})
+		}
+	}
+	sort.SliceStable(errs, func(a, b int) bool { return errs[a].Pos.Offset < errs[b].Pos.Offset })
+	return errs
+}
+
+// ApplyRepairs devuelve s con las reparaciones de errs aplicadas. Los
+// cierres insertados en un mismo lugar quedan del más interno al más externo.
+func ApplyRepairs(s string, errs []BracketError) string {
+	runes := []rune(s)
+	// de atrás hacia adelante, para que los offsets sigan siendo válidos. A
+	// igual offset se borra o reemplaza antes de insertar, y se inserta
+	// primero el cierre de la apertura más externa, así el más interno
+	// termina antes
+	order := make([]BracketError, len(errs))
+	copy(order, errs)
+	sort.SliceStable(order, func(a, b int) bool {
+		ra, rb := order[a].Repair, order[b].Repair
+		if ra.Pos.Offset != rb.Pos.Offset {
+			return ra.Pos.Offset > rb.Pos.Offset
+		}
+		if (ra.Op == Insert) != (rb.Op == Insert) {
+			return rb.Op == Insert
+		}
+		return order[a].Pos.Offset < order[b].Pos.Offset
+	})
+	for _, e := range order {
+		r := e.Repair
+		at := r.Pos.Offset
+		switch r.Op {
+		case Insert:
+			ins := []rune(r.New)
+			// una palabra como "end" no debe pegarse a la palabra vecina
+			if at > 0 && isWordRune(runes[at-1]) && isWordRune(ins[0]) {
+				ins = append([]rune{' '}, ins...)
+			}
+			if at < len(runes) && isWordRune(runes[at]) && isWordRune(ins[len(ins)-1]) {
+				ins = append(ins, ' ')
+			}
+			runes = append(runes[:at], append(ins, runes[at:]...)...)
+		case Delete:
+			runes = append(runes[:at], runes[at+len([]rune(r.Old)):]...)
+		case Replace:
+			runes = append(runes[:at], append([]rune(r.New), runes[at+len([]rune(r.Old)):]...)...)
+		}
+	}
+	return string(runes)
+}
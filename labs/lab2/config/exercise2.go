@@ -3,38 +3,69 @@ package config
 
 import "fmt"
 
-// Stack representa la pila usada en el ejercicio 2.
-type Stack []interface{}
+// Stack es una pila genérica. Si Trace no es nil, se llama en cada push y
+// pop con la operación ("push" o "pop") y el valor.
+type Stack[T any] struct {
+	items []T
+	Trace func(op string, v T)
+}
 
-// Push apila un valor y lo imprime.
-func (s *Stack) Push(value interface{}) {
-	fmt.Println("Pila: push:", value)
-	*s = append(*s, value)
+// PrintTrace es un Trace que imprime cada operación como lo hacía la pila del
+// ejercicio 2: "Pila: push: v" y "Pila: pop v".
+func PrintTrace[T any](op string, v T) {
+	if op == "push" {
+		fmt.Println("Pila: push:", v)
+	} else {
+		fmt.Println("Pila:", op, v)
+	}
+}
+
+// Push apila un valor.
+func (s *Stack[T]) Push(value T) {
+	if s.Trace != nil {
+		s.Trace("push", value)
+	}
+	s.items = append(s.items, value)
 }
 
-// Pop desapila y devuelve el valor; si está vacía, devuelve (nil,false).
-func (s *Stack) Pop() (interface{}, bool) {
-	if len(*s) == 0 {
-		return nil, false
+// Pop desapila y devuelve el valor; si está vacía, devuelve (cero, false).
+func (s *Stack[T]) Pop() (T, bool) {
+	var zero T
+	if len(s.items) == 0 {
+		return zero, false
+	}
+	i := len(s.items) - 1
+	v := s.items[i]
+	s.items[i] = zero
+	s.items = s.items[:i]
+	if s.Trace != nil {
+		s.Trace("pop", v)
 	}
-	i := len(*s) - 1
-	v := (*s)[i]
-	*s = (*s)[:i]
-	fmt.Println("Pila: pop", v)
 	return v, true
 }
 
 // Peek devuelve el tope sin desapilar.
-func (s *Stack) Peek() (interface{}, bool) {
-	if len(*s) == 0 {
-		return nil, false
+func (s *Stack[T]) Peek() (T, bool) {
+	if len(s.items) == 0 {
+		var zero T
+		return zero, false
 	}
-	return (*s)[len(*s)-1], true
+	return s.items[len(s.items)-1], true
 }
 
 // IsEmpty chequea si la pila está vacía.
-func (s *Stack) IsEmpty() bool {
-	return len(*s) == 0
+func (s *Stack[T]) IsEmpty() bool {
+	return len(s.items) == 0
+}
+
+// Len devuelve la cantidad de elementos apilados.
+func (s *Stack[T]) Len() int {
+	return len(s.items)
+}
+
+// Items devuelve una copia de los elementos, del fondo al tope.
+func (s *Stack[T]) Items() []T {
+	return append([]T(nil), s.items...)
 }
 
 // Chequea si el carracter esta dentro del array de strings.
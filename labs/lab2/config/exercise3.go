@@ -6,15 +6,23 @@ import (
 	"unicode"
 )
 
-// Precedencias de operadores
-var OperatorPrecedence = map[rune]int{
-	'(': 1,
-	'|': 2,
-	'.': 3,
-	'*': 4,
-	'+': 4,
-	'?': 4,
-	'^': 5,
+// RegexOperators es la tabla del Shunting Yard para las expresiones
+// regulares: '|' y la concatenación explícita '.' son infijos, '*', '+' y '?'
+// son postfijos y '^' es infijo o, al inicio de una expresión, prefijo.
+var RegexOperators = OperatorTable{
+	Infix: map[string]Operator{
+		"|": {Symbol: "|", Kind: InfixOp, Prec: 2, Assoc: LeftAssoc},
+		".": {Symbol: ".", Kind: InfixOp, Prec: 3, Assoc: LeftAssoc},
+		"^": {Symbol: "^", Kind: InfixOp, Prec: 5, Assoc: LeftAssoc},
+	},
+	Prefix: map[string]Operator{
+		"^": {Symbol: "^", Kind: PrefixOp, Prec: 5},
+	},
+	Postfix: map[string]Operator{
+		"*": {Symbol: "*", Kind: PostfixOp, Prec: 4},
+		"+": {Symbol: "+", Kind: PostfixOp, Prec: 4},
+		"?": {Symbol: "?", Kind: PostfixOp, Prec: 4},
+	},
 }
 
 var (
@@ -49,10 +57,8 @@ func shouldInsertConcat(c1, c2 rune) bool {
 	if c1 == '|' || c1 == '^' {
 		return false
 	}
-	if ContainsRune(AllOperators, c1) || ContainsRune(AllOperators, c2) {
-		return false
-	}
-	return true
+	// después de '*', '+' o '?' sí se concatena: (a|b)*b es (a|b)*.b
+	return !ContainsRune(AllOperators, c2)
 }
 
 func FormatRegex(regex string) string {
@@ -113,56 +119,94 @@ func ExpandRegexExtensions(expr string) string {
 	return result.String()
 }
 
-func InfixToPostfix(rawRegex string) string {
-	expr := rawRegex
-	var output strings.Builder
-	var stack []rune
-
-	for _, c := range expr {
+// TokenizeRegex separa una regex ya formateada (con la concatenación
+// explícita) en tokens para RegexOperators.ToPostfix. Un carácter escapado
+// como \* y cualquier carácter que no sea operador ni paréntesis (letras,
+// dígitos, '[', ']', '{', ',', ';' …) es un operando. Un '.' donde se espera
+// un operando (al inicio, tras '(' o tras un operador infijo) es el carácter
+// literal, como en (.|;), y no la concatenación.
+func TokenizeRegex(expr string) []Token {
+	runes := []rune(expr)
+	var toks []Token
+	expectOperand := true
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
 		switch {
-		case IsAlphanumeric(c) || c == '[' || c == ']':
-			fmt.Printf("Append operando '%c' → output = %s\n", c, output.String())
-			output.WriteRune(c)
+		case c == '\\' && i+1 < len(runes):
+			toks = append(toks, Token{Kind: IdentToken, Text: string(runes[i : i+2]), Pos: i})
+			i++
+			expectOperand = false
 		case c == '(':
-			fmt.Printf("Push '(': stack = %q\n", stack)
-			stack = append(stack, c)
+			toks = append(toks, Token{Kind: LParenToken, Text: "(", Pos: i})
+			expectOperand = true
 		case c == ')':
-			fmt.Println("Encontrado ')', pop hasta '('")
-			for len(stack) > 0 && stack[len(stack)-1] != '(' {
-				top := stack[len(stack)-1]
-				stack = stack[:len(stack)-1]
-				output.WriteRune(top)
-				fmt.Printf("  Pop '%c' → output = %s\n", top, output.String())
-			}
-			if len(stack) > 0 {
-				fmt.Printf("  Pop '(': stack = %q\n", stack)
-				stack = stack[:len(stack)-1]
+			toks = append(toks, Token{Kind: RParenToken, Text: ")", Pos: i})
+			expectOperand = false
+		case c == '.' && expectOperand:
+			toks = append(toks, Token{Kind: IdentToken, Text: ".", Pos: i})
+			expectOperand = false
+		case c == '.' || ContainsRune(AllOperators, c):
+			toks = append(toks, Token{Kind: OpToken, Text: string(c), Pos: i})
+			if _, postfix := RegexOperators.Postfix[string(c)]; !postfix {
+				expectOperand = true
 			}
 		default:
-			precC := OperatorPrecedence[c]
-			fmt.Printf("Operador '%c' (precedencia %d) encontrado\n", c, precC)
-			for len(stack) > 0 {
-				top := stack[len(stack)-1]
-				precTop := OperatorPrecedence[top]
-				if precTop >= precC {
-					stack = stack[:len(stack)-1]
-					output.WriteRune(top)
-					fmt.Printf("  Pop '%c' (prec %d ≥ %d) → output = %s\n", top, precTop, precC, output.String())
-					continue
-				}
-				break
-			}
-			stack = append(stack, c)
-			fmt.Printf("Push '%c': stack = %q\n", c, stack)
+			toks = append(toks, Token{Kind: IdentToken, Text: string(c), Pos: i})
+			expectOperand = false
+		}
+	}
+	return toks
+}
+
+// PrintRegexTrace es un Trace para RegexOperators que imprime cada paso como
+// lo hacía la conversión del ejercicio 3: el operador y su precedencia, cada
+// push con la pila y cada pop con la salida.
+func PrintRegexTrace(ev TraceEvent) {
+	output := regexPostfix(ev.Output)
+	switch ev.Step {
+	case TraceOperand:
+		fmt.Printf("Append operando '%s' → output = %s\n", ev.Text, output)
+	case TraceOperator:
+		fmt.Printf("Operador '%s' (precedencia %d) encontrado\n", ev.Text, ev.Prec)
+	case TracePush:
+		fmt.Printf("Push '%s': stack = %q\n", ev.Text, ev.Stack)
+	case TracePop:
+		if ev.Incoming > 0 {
+			fmt.Printf("  Pop '%s' (prec %d ≥ %d) → output = %s\n", ev.Text, ev.Prec, ev.Incoming, output)
+		} else {
+			fmt.Printf("  Pop '%s' → output = %s\n", ev.Text, output)
 		}
+	case TraceAppend:
+		fmt.Printf("Append operador '%s' → output = %s\n", ev.Text, output)
+	case TraceCloseParen:
+		fmt.Printf("Encontrado '%s', pop hasta '('\n", ev.Text)
+	case TracePopParen:
+		fmt.Printf("  Pop '(': stack = %q\n", ev.Stack)
+	case TraceEnd:
+		fmt.Println("Fin de input, vaciando pila:")
 	}
+}
 
-	fmt.Println("Fin de input, vaciando pila:")
-	for len(stack) > 0 {
-		top := stack[len(stack)-1]
-		stack = stack[:len(stack)-1]
-		output.WriteRune(top)
-		fmt.Printf("  Pop '%c' → output = %s\n", top, output.String())
+// regexPostfix une los elementos sin separador, como se escribe una regex.
+func regexPostfix(items []Item) string {
+	var b strings.Builder
+	for _, it := range items {
+		b.WriteString(it.String())
+	}
+	return b.String()
+}
+
+// InfixToPostfix convierte una regex formateada a postfix con
+// RegexOperators.ToPostfix, el mismo Shunting Yard que usan las expresiones
+// aritméticas. Si trace es true imprime cada paso con PrintRegexTrace.
+func InfixToPostfix(regex string, trace bool) (string, error) {
+	table := RegexOperators
+	if trace {
+		table.Trace = PrintRegexTrace
+	}
+	items, err := table.ToPostfix(TokenizeRegex(regex))
+	if err != nil {
+		return "", err
 	}
-	return output.String()
+	return regexPostfix(items), nil
 }
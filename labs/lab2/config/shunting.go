@@ -0,0 +1,357 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Assoc es la asociatividad de un operador binario.
+type Assoc int
+
+const (
+	LeftAssoc  Assoc = iota // a-b-c = (a-b)-c
+	RightAssoc              // a^b^c = a^(b^c)
+)
+
+// OpKind indica dónde va un operador respecto de sus operandos.
+type OpKind int
+
+const (
+	InfixOp   OpKind = iota // a + b
+	PrefixOp                // -a
+	PostfixOp               // a!
+)
+
+// Operator describe un operador de una tabla. Name es como se escribe en
+// postfix; si está vacío se usa Symbol (sirve para distinguir el menos
+// unario, "neg", del binario).
+type Operator struct {
+	Symbol string
+	Name   string
+	Kind   OpKind
+	Prec   int
+	Assoc  Assoc
+}
+
+// PostfixName devuelve el nombre del operador en notación postfix.
+func (o Operator) PostfixName() string {
+	if o.Name != "" {
+		return o.Name
+	}
+	return o.Symbol
+}
+
+// OperatorTable es la configuración del Shunting Yard generalizado: los
+// operadores de cada clase y las funciones con su aridad. Un mismo símbolo
+// puede ser infijo y prefijo, como '-'; se decide por el contexto.
+type OperatorTable struct {
+	Infix     map[string]Operator
+	Prefix    map[string]Operator
+	Postfix   map[string]Operator
+	Functions map[string]int // aridad; VariadicArity acepta uno o más argumentos
+
+	// Trace, si no es nil, recibe cada paso del algoritmo.
+	Trace func(ev TraceEvent)
+}
+
+// TraceStep es la acción de un paso del Shunting Yard.
+type TraceStep int
+
+const (
+	TraceOperand    TraceStep = iota // un operando pasa a la salida
+	TraceOperator                    // aparece un operador en la entrada
+	TracePush                        // se apila un operador, un '(' o una función
+	TracePop                         // un operador o una llamada pasa de la pila a la salida
+	TraceAppend                      // un operador postfijo pasa directo a la salida
+	TraceCloseParen                  // aparece ')' o ',': se desapila hasta '('
+	TracePopParen                    // se descarta el '(' que cierra un ')'
+	TraceEnd                         // fin de la entrada: se vacía la pila
+)
+
+// TraceEvent describe un paso del Shunting Yard. Stack y Output son el estado
+// después del paso; Stack va del fondo al tope.
+type TraceEvent struct {
+	Step     TraceStep
+	Text     string // el token o la entrada de la pila
+	Prec     int    // precedencia del operador; 0 para operandos y paréntesis
+	Incoming int    // en un TracePop por precedencia, la del operador que llega
+	Stack    []string
+	Output   []Item
+}
+
+// VariadicArity marca una función que acepta uno o más argumentos.
+const VariadicArity = -1
+
+// TokenKind clasifica los tokens que recibe el Shunting Yard.
+type TokenKind int
+
+const (
+	NumberToken TokenKind = iota
+	IdentToken
+	OpToken
+	LParenToken
+	RParenToken
+	CommaToken
+)
+
+// Token es un token de la entrada; Pos es su posición en runas, desde 0.
+type Token struct {
+	Kind TokenKind
+	Text string
+	Pos  int
+}
+
+// ItemKind clasifica los elementos de la salida postfix.
+type ItemKind int
+
+const (
+	OperandItem ItemKind = iota // número o variable
+	OperatorItem
+	CallItem // llamada a función con Args argumentos
+)
+
+// Item es un elemento de la expresión en postfix.
+type Item struct {
+	Kind   ItemKind
+	Text   string   // número, variable o nombre de la función
+	Number bool     // para OperandItem: Text es un número y no una variable
+	Op     Operator // para OperatorItem
+	Args   int      // para CallItem
+	Pos    int
+}
+
+// String escribe el elemento como aparece en postfix; las llamadas llevan su
+// aridad, p. ej. "max/2".
+func (it Item) String() string {
+	switch it.Kind {
+	case OperatorItem:
+		return it.Op.PostfixName()
+	case CallItem:
+		return it.Text + "/" + strconv.Itoa(it.Args)
+	default:
+		return it.Text
+	}
+}
+
+// FormatPostfix une los elementos separados por espacios.
+func FormatPostfix(items []Item) string {
+	parts := make([]string, len(items))
+	for i, it := range items {
+		parts[i] = it.String()
+	}
+	return strings.Join(parts, " ")
+}
+
+// SyntaxError es un error de la expresión en una posición (en runas, desde 0).
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("columna %d: %s", e.Pos+1, e.Msg)
+}
+
+// stackEntry es un elemento de la pila de operadores: un operador, un '(' o
+// el nombre de una función cuyo '(' está justo encima.
+type stackEntry struct {
+	kind  TokenKind // OpToken, LParenToken o IdentToken (función)
+	op    Operator
+	text  string
+	pos   int
+	call  bool // para '(': abre los argumentos de una función
+	arity int  // para '(' de función: argumentos vistos hasta ahora
+}
+
+// String escribe la entrada como aparece en la expresión.
+func (e stackEntry) String() string {
+	switch e.kind {
+	case OpToken:
+		return e.op.Symbol
+	case LParenToken:
+		return "("
+	default:
+		return e.text
+	}
+}
+
+// ToPostfix convierte tokens infix a postfix con el algoritmo de Shunting
+// Yard: los operadores respetan precedencia y asociatividad, un operador que
+// aparece donde se espera un operando es prefijo (menos unario) y un
+// identificador seguido de '(' es una llamada a función.
+func (t OperatorTable) ToPostfix(tokens []Token) ([]Item, error) {
+	var out []Item
+	var stack Stack[stackEntry]
+	expectOperand := true
+
+	trace := func(step TraceStep, text string, prec, incoming int) {
+		if t.Trace == nil {
+			return
+		}
+		entries := stack.Items()
+		names := make([]string, len(entries))
+		for i, e := range entries {
+			names[i] = e.String()
+		}
+		t.Trace(TraceEvent{Step: step, Text: text, Prec: prec, Incoming: incoming, Stack: names, Output: out})
+	}
+	// push apila e y lo anota en la traza
+	push := func(e stackEntry) {
+		stack.Push(e)
+		trace(TracePush, e.String(), e.op.Prec, 0)
+	}
+	// emit pasa a la salida un operador desapilado
+	emit := func(e stackEntry, incoming int) {
+		out = append(out, Item{Kind: OperatorItem, Op: e.op, Pos: e.pos})
+		trace(TracePop, e.String(), e.op.Prec, incoming)
+	}
+
+	// popOperators saca a la salida los operadores que ligan más fuerte que o
+	popOperators := func(o Operator) {
+		for {
+			top, ok := stack.Peek()
+			if !ok || top.kind != OpToken {
+				return
+			}
+			if top.op.Prec > o.Prec || (top.op.Prec == o.Prec && o.Assoc == LeftAssoc) {
+				stack.Pop()
+				emit(top, o.Prec)
+				continue
+			}
+			return
+		}
+	}
+
+	for i, tok := range tokens {
+		switch tok.Kind {
+		case NumberToken, IdentToken:
+			if !expectOperand {
+				return nil, &SyntaxError{tok.Pos, fmt.Sprintf("falta un operador antes de %q", tok.Text)}
+			}
+			if _, isFunc := t.Functions[tok.Text]; isFunc && tok.Kind == IdentToken &&
+				i+1 < len(tokens) && tokens[i+1].Kind == LParenToken {
+				push(stackEntry{kind: IdentToken, text: tok.Text, pos: tok.Pos})
+				continue
+			}
+			out = append(out, Item{Kind: OperandItem, Text: tok.Text, Number: tok.Kind == NumberToken, Pos: tok.Pos})
+			trace(TraceOperand, tok.Text, 0, 0)
+			expectOperand = false
+
+		case OpToken:
+			if expectOperand {
+				o, ok := t.Prefix[tok.Text]
+				if !ok {
+					return nil, &SyntaxError{tok.Pos, fmt.Sprintf("se esperaba un operando y se encontró %q", tok.Text)}
+				}
+				trace(TraceOperator, tok.Text, o.Prec, 0)
+				push(stackEntry{kind: OpToken, op: o, pos: tok.Pos})
+				continue
+			}
+			if o, ok := t.Postfix[tok.Text]; ok {
+				trace(TraceOperator, tok.Text, o.Prec, 0)
+				popOperators(o)
+				out = append(out, Item{Kind: OperatorItem, Op: o, Pos: tok.Pos})
+				trace(TraceAppend, tok.Text, o.Prec, 0)
+				continue
+			}
+			o, ok := t.Infix[tok.Text]
+			if !ok {
+				return nil, &SyntaxError{tok.Pos, fmt.Sprintf("operador desconocido %q", tok.Text)}
+			}
+			trace(TraceOperator, tok.Text, o.Prec, 0)
+			popOperators(o)
+			push(stackEntry{kind: OpToken, op: o, pos: tok.Pos})
+			expectOperand = true
+
+		case LParenToken:
+			if !expectOperand {
+				return nil, &SyntaxError{tok.Pos, "falta un operador antes de '('"}
+			}
+			top, _ := stack.Peek()
+			call := stack.Len() > 0 && top.kind == IdentToken && i > 0 && tokens[i-1].Kind == IdentToken
+			entry := stackEntry{kind: LParenToken, pos: tok.Pos, call: call}
+			expectOperand = true
+			if call {
+				entry.arity = 1
+				if i+1 < len(tokens) && tokens[i+1].Kind == RParenToken {
+					entry.arity = 0
+					expectOperand = false // f() no tiene argumentos
+				}
+			}
+			push(entry)
+
+		case CommaToken, RParenToken:
+			if expectOperand {
+				return nil, &SyntaxError{tok.Pos, fmt.Sprintf("falta un operando antes de %q", tok.Text)}
+			}
+			trace(TraceCloseParen, tok.Text, 0, 0)
+			for {
+				top, ok := stack.Peek()
+				if !ok {
+					if tok.Kind == CommaToken {
+						return nil, &SyntaxError{tok.Pos, "',' fuera de una llamada a función"}
+					}
+					return nil, &SyntaxError{tok.Pos, "')' sin '(' que lo abra"}
+				}
+				if top.kind == LParenToken {
+					break
+				}
+				stack.Pop()
+				emit(top, 0)
+			}
+			paren, _ := stack.Pop()
+			if tok.Kind == CommaToken {
+				if !paren.call {
+					return nil, &SyntaxError{tok.Pos, "',' fuera de una llamada a función"}
+				}
+				paren.arity++
+				stack.Push(paren)
+				expectOperand = true
+				continue
+			}
+			if paren.call {
+				fn, _ := stack.Pop()
+				want := t.Functions[fn.text]
+				if (want != VariadicArity && want != paren.arity) || (want == VariadicArity && paren.arity == 0) {
+					return nil, &SyntaxError{fn.pos, fmt.Sprintf("%s recibe %s, no %d", fn.text, arityText(want), paren.arity)}
+				}
+				out = append(out, Item{Kind: CallItem, Text: fn.text, Args: paren.arity, Pos: fn.pos})
+				trace(TracePop, out[len(out)-1].String(), 0, 0)
+			} else {
+				trace(TracePopParen, "(", 0, 0)
+			}
+			expectOperand = false
+		}
+	}
+
+	if expectOperand {
+		end := 0
+		if len(tokens) > 0 {
+			last := tokens[len(tokens)-1]
+			end = last.Pos + len([]rune(last.Text))
+		}
+		return nil, &SyntaxError{end, "la expresión termina sin operando"}
+	}
+	trace(TraceEnd, "", 0, 0)
+	for !stack.IsEmpty() {
+		top, _ := stack.Pop()
+		if top.kind == LParenToken {
+			return nil, &SyntaxError{top.pos, "'(' sin cerrar"}
+		}
+		emit(top, 0)
+	}
+	return out, nil
+}
+
+// arityText describe una aridad para los mensajes de error.
+func arityText(n int) string {
+	switch n {
+	case VariadicArity:
+		return "uno o más argumentos"
+	case 1:
+		return "1 argumento"
+	default:
+		return fmt.Sprintf("%d argumentos", n)
+	}
+}
@@ -2,16 +2,30 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"lab2/config"
 	"log"
 	"os"
-	"strings"
 )
 
 // Function Main
 func main() {
-	file, err := os.Open("expressions2.txt")
+	inPath := flag.String("in", "expressions2.txt", "archivo con una expresión por línea")
+	extra := flag.String("pairs", "", "pares adicionales apertura:cierre, p. ej. begin:end,if:fi")
+	trace := flag.Bool("trace", true, "imprimir cada push y pop de la pila")
+	flag.Parse()
+
+	userPairs, err := config.ParsePairs(*extra, config.Pairs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	checker := config.NewBracketChecker(config.Pairs, userPairs)
+	if *trace {
+		checker.Trace = config.PrintTrace[string]
+	}
+
+	file, err := os.Open(*inPath)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -20,37 +34,24 @@ func main() {
 	scanner := bufio.NewScanner(file)
 
 	for scanner.Scan() {
-		var stack config.Stack
-
 		line := scanner.Text()
 		fmt.Println("⚪ Expresión: ", line)
-		char := strings.Split(line, "")
 
-		for i, c := range char {
-			if config.ContainsChar(config.OpenBrackets, c) { // For opening characters
-				stack.Push(c)
-			}
-			if config.ContainsChar(config.CloseBrackets, c) { // For closing characters
-				if stack.IsEmpty() {
-					fmt.Printf("Pila vacía al encontrar %s — 🔴 No balanceada\n", c)
-					break
-				}
-				top, _ := stack.Peek()
-				if config.Pairs[c] == top {
-					stack.Pop()
-				} else {
-					fmt.Printf("🔴 Pila: se esperaba %s pero se encontró %s\n", config.Pairs[c], top)
-					break
-				}
+		errs := checker.Check(line)
+		if len(errs) == 0 {
+			fmt.Println("🟢 Expresión balanceada")
+		} else {
+			for _, e := range errs {
+				fmt.Printf("🔴 %v — sugerencia: %v\n", e, e.Repair)
 			}
-			if len(char)-1 == i {
-				if stack.IsEmpty() {
-					fmt.Println("🟢 Expresión balanceada")
-				}
+			changes := "cambios"
+			if len(errs) == 1 {
+				changes = "cambio"
 			}
+			fmt.Printf("🔧 Reparación mínima (%d %s): %s\n", len(errs), changes, config.ApplyRepairs(line, errs))
 		}
 		fmt.Println("< — — — — — — — — — — — - - - - - >")
-		fmt.Println("\n")
+		fmt.Println()
 	}
 	// If is an error in scann
 	if err := scanner.Err(); err != nil {
@@ -22,7 +22,11 @@ func main() {
 		fmt.Println("⚪ Expresión Infix: ", line)
 		expanded := config.ExpandRegexExtensions(line)
 		formatted := config.FormatRegex(expanded)
-		postfix := config.InfixToPostfix(formatted)
+		postfix, err := config.InfixToPostfix(formatted, true)
+		if err != nil {
+			fmt.Println("🔴 Error: ", err)
+			continue
+		}
 		fmt.Println("🟢 Expresión: ", postfix)
 	}
 
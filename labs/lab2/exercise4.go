@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"lab2/config"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	inPath := flag.String("in", "expressions4.txt", "archivo con una expresión o asignación por línea")
+	varsSpec := flag.String("vars", "", "valores iniciales de variables, p. ej. x=1,y=2.5")
+	flag.Parse()
+
+	vars, err := parseVars(*varsSpec)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := os.Open(*inPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fmt.Println("⚪ Expresión: ", line)
+		name, expr, offset := config.SplitAssignment(line)
+
+		items, tree, err := config.CompileArith(expr)
+		if err == nil {
+			fmt.Println("🔵 Postfix: ", config.FormatPostfix(items))
+			fmt.Println("🌳 Árbol:")
+			fmt.Print(tree.TreeString())
+			var value float64
+			if value, err = tree.Eval(vars); err == nil {
+				if name != "" {
+					vars[name] = value
+					fmt.Printf("🟢 %s = %s\n", name, config.FormatNumber(value))
+				} else {
+					fmt.Println("🟢 Valor: ", config.FormatNumber(value))
+				}
+			}
+		}
+		if err != nil {
+			// las posiciones se cuentan desde el inicio de la línea
+			var se *config.SyntaxError
+			if errors.As(err, &se) {
+				se.Pos += offset
+			}
+			fmt.Println("🔴 Error: ", err)
+		}
+		fmt.Println("< — — — — — — — — — — — - - - - - >")
+		fmt.Println()
+	}
+
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseVars lee "x=1,y=2.5" como valores iniciales de variables.
+func parseVars(spec string) (map[string]float64, error) {
+	vars := make(map[string]float64)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("variable inválida %q, se espera nombre=valor", item)
+		}
+		vars[strings.TrimSpace(name)] = v
+	}
+	return vars, nil
+}
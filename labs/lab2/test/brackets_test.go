@@ -0,0 +1,149 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"lab2/config"
+)
+
+func TestParsePairs(t *testing.T) {
+	got, err := config.ParsePairs(" begin:end, if:fi ,,begin:end", config.Pairs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"end": "begin", "fi": "if"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePairs = %v, want %v", got, want)
+	}
+	for _, bad := range []string{
+		"(:]",     // ( already closes with )
+		"<:)",     // ) already closes (
+		"a:b,a:c", // same opener, two closers
+		"a:c,b:c", // same closer, two openers
+		"a:b,b:c", // b is both a closer and an opener
+		")::",     // ) is a closer
+		"begin",   // no closer
+		"x:x",     // opener equal to closer
+		":end",    // empty opener
+	} {
+		if _, err := config.ParsePairs(bad, config.Pairs); err == nil {
+			t.Errorf("ParsePairs(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	c := config.NewBracketChecker(config.Pairs, map[string]string{"end": "begin"})
+	cases := []struct {
+		in    string
+		kinds []config.BracketErrorKind
+		fixed string
+	}{
+		{"a(b[c]{d})", nil, "a(b[c]{d})"},
+		{"", nil, ""},
+		{"(", []config.BracketErrorKind{config.Unclosed}, "()"},
+		{")", []config.BracketErrorKind{config.Unopened}, ""},
+		{"(]", []config.BracketErrorKind{config.Mismatched}, "()"},
+		{"a(a|b])*b", []config.BracketErrorKind{config.Unopened}, "a(a|b)*b"},
+		{"((", []config.BracketErrorKind{config.Mismatched}, "()"},
+		{"])", []config.BracketErrorKind{config.Unopened}, "()"},
+		{"{[(", []config.BracketErrorKind{config.Unclosed, config.Mismatched}, "{[]}"},
+		{"a(b", []config.BracketErrorKind{config.Unclosed}, "a(b)"},
+		{"begin x(", []config.BracketErrorKind{config.Mismatched}, "begin xend"},
+		{"begin x", []config.BracketErrorKind{config.Unclosed}, "begin x end"},
+		{"blend endless begin end", nil, "blend endless begin end"},
+	}
+	for _, tc := range cases {
+		errs := c.Check(tc.in)
+		var kinds []config.BracketErrorKind
+		for _, e := range errs {
+			kinds = append(kinds, e.Kind)
+		}
+		if !reflect.DeepEqual(kinds, tc.kinds) {
+			t.Errorf("Check(%q) kinds = %v, want %v (%v)", tc.in, kinds, tc.kinds, errs)
+		}
+		if got := config.ApplyRepairs(tc.in, errs); got != tc.fixed {
+			t.Errorf("ApplyRepairs(%q) = %q, want %q", tc.in, got, tc.fixed)
+		}
+	}
+}
+
+func TestCheckPositions(t *testing.T) {
+	errs := config.NewBracketChecker(config.Pairs).Check("ab\n(c]")
+	if len(errs) != 1 {
+		t.Fatalf("Check = %v, want one error", errs)
+	}
+	e := errs[0]
+	want := config.BracketError{
+		Kind:    config.Mismatched,
+		Delim:   "]",
+		Pos:     config.Position{Offset: 5, Line: 2, Column: 3},
+		Open:    "(",
+		OpenPos: config.Position{Offset: 3, Line: 2, Column: 1},
+		Repair:  config.Repair{Op: config.Replace, Pos: config.Position{Offset: 5, Line: 2, Column: 3}, Old: "]", New: ")"},
+	}
+	if e != want {
+		t.Errorf("Check = %+v, want %+v", e, want)
+	}
+}
+
+// editDistance is the least number of single-delimiter insertions,
+// deletions and replacements that balance s, found by breadth-first search.
+func editDistance(c *config.BracketChecker, s string, delims []string) int {
+	seen := map[string]bool{s: true}
+	level := []string{s}
+	for d := 0; ; d++ {
+		var next []string
+		for _, w := range level {
+			if c.Check(w) == nil {
+				return d
+			}
+			add := func(v string) {
+				if !seen[v] {
+					seen[v] = true
+					next = append(next, v)
+				}
+			}
+			for i := 0; i <= len(w); i++ {
+				for _, x := range delims {
+					add(w[:i] + x + w[i:])
+				}
+				if i < len(w) {
+					add(w[:i] + w[i+1:])
+					for _, x := range delims {
+						add(w[:i] + x + w[i+1:])
+					}
+				}
+			}
+		}
+		level = next
+	}
+}
+
+// Every string of up to four delimiters over (), [] is repaired with the
+// least number of edits, and the repair balances it.
+func TestRepairIsMinimal(t *testing.T) {
+	c := config.NewBracketChecker(config.Pairs)
+	delims := []string{"(", ")", "[", "]"}
+	inputs := []string{""}
+	level := []string{""}
+	for l := 1; l <= 4; l++ {
+		var next []string
+		for _, w := range level {
+			for _, d := range delims {
+				next = append(next, w+d)
+			}
+		}
+		inputs = append(inputs, next...)
+		level = next
+	}
+	for _, s := range inputs {
+		errs := c.Check(s)
+		if want := editDistance(c, s, delims); len(errs) != want {
+			t.Errorf("Check(%q) proposes %d edits, want %d", s, len(errs), want)
+		}
+		if fixed := config.ApplyRepairs(s, errs); c.Check(fixed) != nil {
+			t.Errorf("ApplyRepairs(%q) = %q is not balanced", s, fixed)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"lab2/config"
+)
+
+func TestRegexToPostfix(t *testing.T) {
+	cases := []struct {
+		infix, formatted, postfix string
+	}{
+		{"ab", "a.b", "ab."},
+		{"a|b", "a|b", "ab|"},
+		{"ab|c", "a.b|c", "ab.c|"},
+		{"a|bc", "a|b.c", "abc.|"},
+		{"(a|b)*b", "(a|b)*.b", "ab|*b."},
+		{"a*b*", "a*.b*", "a*b*."},
+		{"(ab)*c", "(a.b)*.c", "ab.*c."},
+		{"a(b|c)", "a.(b|c)", "abc|."},
+		{`\*a`, `\*.a`, `\*a.`},
+		{"[az]", "[.a.z.]", "[a.z.]."},
+		{"^ab", "^a.b", "a^b."},
+		{"(.|;)", "(.|;)", ".;|"},
+	}
+	for _, c := range cases {
+		if got := config.FormatRegex(c.infix); got != c.formatted {
+			t.Errorf("FormatRegex(%q) = %q, want %q", c.infix, got, c.formatted)
+			continue
+		}
+		got, err := config.InfixToPostfix(c.formatted, false)
+		if err != nil {
+			t.Errorf("InfixToPostfix(%q): %v", c.formatted, err)
+			continue
+		}
+		if got != c.postfix {
+			t.Errorf("InfixToPostfix(%q) = %q, want %q", c.formatted, got, c.postfix)
+		}
+	}
+}
+
+func TestRegexToPostfixErrors(t *testing.T) {
+	for _, bad := range []string{"a|", "(a|b", "a|b)", "*a", "a.|b", "a.*", ""} {
+		if got, err := config.InfixToPostfix(bad, false); err == nil {
+			t.Errorf("InfixToPostfix(%q) = %q, want an error", bad, got)
+		}
+	}
+}
+
+// TestExpressions3 converts every line of the file exercise3.go reads.
+func TestExpressions3(t *testing.T) {
+	data, err := os.ReadFile("../expressions3.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		formatted := config.FormatRegex(config.ExpandRegexExtensions(line))
+		if got, err := config.InfixToPostfix(formatted, false); err != nil || got == "" {
+			t.Errorf("line %d %q: InfixToPostfix(%q) = %q, %v", i+1, line, formatted, got, err)
+		}
+	}
+}
+
+func TestRegexTrace(t *testing.T) {
+	var steps []string
+	table := config.RegexOperators
+	table.Trace = func(ev config.TraceEvent) {
+		var out strings.Builder
+		for _, it := range ev.Output {
+			out.WriteString(it.String())
+		}
+		steps = append(steps, fmt.Sprintf("%d %s %d %d %v %s", ev.Step, ev.Text, ev.Prec, ev.Incoming, ev.Stack, out.String()))
+	}
+	if _, err := table.ToPostfix(config.TokenizeRegex("a.(b|c)*")); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"0 a 0 0 [] a",       // operand
+		"1 . 3 0 [] a",       // operator
+		"2 . 3 0 [.] a",      // push
+		"2 ( 0 0 [. (] a",    // push
+		"0 b 0 0 [. (] ab",   // operand
+		"1 | 2 0 [. (] ab",   // operator
+		"2 | 2 0 [. ( |] ab", // push
+		"0 c 0 0 [. ( |] abc",
+		"5 ) 0 0 [. ( |] abc", // close paren
+		"3 | 2 0 [. (] abc|",  // pop
+		"6 ( 0 0 [.] abc|",    // pop paren
+		"1 * 4 0 [.] abc|",    // operator
+		"4 * 4 0 [.] abc|*",   // append
+		"7  0 0 [.] abc|*",    // end
+		"3 . 3 0 [] abc|*.",   // pop
+	}
+	if strings.Join(steps, "\n") != strings.Join(want, "\n") {
+		t.Errorf("trace:\n%s\nwant:\n%s", strings.Join(steps, "\n"), strings.Join(want, "\n"))
+	}
+
+	// a pop forced by precedence carries both precedences
+	steps = nil
+	if _, err := table.ToPostfix(config.TokenizeRegex("a.b|c")); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(steps, "3 . 3 2 [] ab.") {
+		t.Errorf("no precedence pop of '.' in trace:\n%s", strings.Join(steps, "\n"))
+	}
+}
+
+func TestArithToPostfix(t *testing.T) {
+	cases := []struct {
+		expr, postfix string
+	}{
+		{"1 + 2 * 3", "1 2 3 * +"},
+		{"(1 + 2) * 3", "1 2 + 3 *"},
+		{"8 - 3 - 2", "8 3 - 2 -"},
+		{"2 ^ 3 ^ 2", "2 3 2 ^ ^"},
+		{"-2 ^ 2", "2 2 ^ neg"},
+		{"2 ^ -1", "2 1 neg ^"},
+		{"3! + 1", "3 ! 1 +"},
+		{"max(1, x, 3) * 2", "1 x 3 max/3 2 *"},
+		{"pow(2, 1 + 1)", "2 1 1 + pow/2"},
+		{"1.5e3 / +x", "1.5e3 x pos /"},
+	}
+	for _, c := range cases {
+		items, _, err := config.CompileArith(c.expr)
+		if err != nil {
+			t.Errorf("CompileArith(%q): %v", c.expr, err)
+			continue
+		}
+		if got := config.FormatPostfix(items); got != c.postfix {
+			t.Errorf("CompileArith(%q) = %q, want %q", c.expr, got, c.postfix)
+		}
+	}
+}
+
+func TestArithErrors(t *testing.T) {
+	cases := []struct {
+		expr string
+		col  int // 0: not a SyntaxError
+	}{
+		{"1 +", 4},
+		{"(1 + 2", 1},
+		{"1 + 2)", 6},
+		{"1 2", 3},
+		{"max()", 1},
+		{"abs(1, 2)", 1},
+		{"1, 2", 2},
+		{"2 # 3", 3},
+		{"", 0},
+	}
+	for _, c := range cases {
+		_, _, err := config.CompileArith(c.expr)
+		if err == nil {
+			t.Errorf("CompileArith(%q) succeeded", c.expr)
+			continue
+		}
+		var se *config.SyntaxError
+		if ok := errors.As(err, &se); ok != (c.col > 0) || (ok && se.Pos+1 != c.col) {
+			t.Errorf("CompileArith(%q) = %v, want an error at column %d", c.expr, err, c.col)
+		}
+	}
+}
+
+func TestArithEval(t *testing.T) {
+	vars := map[string]float64{"x": 2, "inf": 7}
+	cases := []struct {
+		expr string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"2 ^ 3 ^ 2", 512},
+		{"-2 ^ 2", -4},
+		{"x * max(1, x, 3)", 6},
+		{"4! / 2", 12},
+		{"inf + 1", 8}, // a variable, not +Inf
+		{"log(2, 8)", 3},
+		{"7 % 4", 3},
+	}
+	for _, c := range cases {
+		_, tree, err := config.CompileArith(c.expr)
+		if err != nil {
+			t.Fatalf("CompileArith(%q): %v", c.expr, err)
+		}
+		got, err := tree.Eval(vars)
+		if err != nil || math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("Eval(%q) = %v, %v, want %v", c.expr, got, err, c.want)
+		}
+	}
+
+	// identifiers that strconv.ParseFloat would read as numbers are variables
+	for _, name := range []string{"inf", "Inf", "nan", "NaN", "infinity"} {
+		_, tree, err := config.CompileArith(name + " * 0")
+		if err != nil {
+			t.Fatalf("CompileArith(%q): %v", name, err)
+		}
+		if v, err := tree.Eval(nil); err == nil {
+			t.Errorf("Eval(%s * 0) = %v, want an unset variable error", name, v)
+		}
+	}
+	for _, bad := range []string{"1 / 0", "(-1)!", "sqrt(-1)", "y + 1"} {
+		_, tree, err := config.CompileArith(bad)
+		if err != nil {
+			t.Fatalf("CompileArith(%q): %v", bad, err)
+		}
+		if v, err := tree.Eval(vars); err == nil {
+			t.Errorf("Eval(%q) = %v, want an error", bad, v)
+		}
+	}
+}
@@ -1,117 +1,207 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strconv"
+	"strings"
 )
 
+// Node es un nodo del árbol sintáctico. Además del operador o símbolo
+// (Label), guarda la subexpresión que representa, si acepta ε y las primeras
+// cadenas de su lenguaje (en orden de longitud y luego alfabético).
 type Node struct {
 	ID    int
 	Label string
 	Left  *Node
 	Right *Node
+
+	Expr     string   // subexpresión en infix, con los paréntesis mínimos
+	Nullable bool     // ε ∈ L(Expr)
+	Samples  []string // primeras cadenas de L(Expr); "" es ε
+	More     bool     // L(Expr) tiene más cadenas que las de Samples
+
+	lang    byLength
+	longest int // longitud de la cadena más larga de L(Expr); math.MaxInt si es infinito
 }
 
-var nodeIdCounter int = 0
+// TreeOptions controla las anotaciones que calcula BuildTree. Ninguno de
+// los dos campos puede ser negativo.
+type TreeOptions struct {
+	Samples   int // cadenas de ejemplo por nodo
+	MaxLength int // longitud máxima de las cadenas de ejemplo
+}
 
-func PostfixToTree(postfix string) *Node {
+// Validate devuelve un error si alguna opción es negativa.
+func (o TreeOptions) Validate() error {
+	if o.Samples < 0 {
+		return fmt.Errorf("Samples no puede ser negativo (%d)", o.Samples)
+	}
+	if o.MaxLength < 0 {
+		return fmt.Errorf("MaxLength no puede ser negativo (%d)", o.MaxLength)
+	}
+	return nil
+}
+
+// DefaultTreeOptions son las opciones que usa PostfixToTree.
+var DefaultTreeOptions = TreeOptions{Samples: 4, MaxLength: 6}
+
+// BuildTree construye el árbol sintáctico de una expresión postfix y anota
+// cada nodo. Los IDs se numeran desde 0 en cada llamada, así que se pueden
+// construir varios árboles, incluso en paralelo. Devuelve un error si opts
+// no es válido.
+func BuildTree(postfix string, opts TreeOptions) (*Node, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
 	var stack []*Node
+	nextID := 0
+	newNode := func(label string, left, right *Node) *Node {
+		n := &Node{ID: nextID, Label: label, Left: left, Right: right}
+		nextID++
+		n.annotate(opts)
+		return n
+	}
 
 	for _, i := range postfix {
 		if IsAlphanumeric(i) { // Alfanuméricos
-			node := &Node{
-				ID:    nodeIdCounter,
-				Label: string(i),
-			}
-			nodeIdCounter++
-			stack = append(stack, node)
+			stack = append(stack, newNode(string(i), nil, nil))
 		} else if i == '|' || i == '.' { // operadores binarios
 			if len(stack) < 2 {
-				fmt.Println("Error: pila insuficiente para operador binario", string(i))
-				return nil
+				return nil, fmt.Errorf("pila insuficiente para operador binario %s", string(i))
 			}
 			right := stack[len(stack)-1]
 			left := stack[len(stack)-2]
 			stack = stack[:len(stack)-2]
-
-			node := &Node{
-				ID:    nodeIdCounter,
-				Label: string(i),
-				Left:  left,
-				Right: right,
-			}
-			nodeIdCounter++
-			stack = append(stack, node)
+			stack = append(stack, newNode(string(i), left, right))
 		} else if i == '*' { // Operador unitario
 			if len(stack) < 1 {
-				fmt.Println("Error: pila insuficiente para operador unario '*'")
-				return nil
+				return nil, errors.New("pila insuficiente para operador unario '*'")
 			}
 			child := stack[len(stack)-1]
 			stack = stack[:len(stack)-1]
-
-			node := &Node{
-				ID:    nodeIdCounter,
-				Label: string(i),
-				Left:  child,
-			}
-			nodeIdCounter++
-			stack = append(stack, node)
+			stack = append(stack, newNode(string(i), child, nil))
 		}
 	}
 	if len(stack) != 1 {
-		fmt.Println("Error: expresión inválida, pila final no tiene un único árbol")
-		return nil
+		return nil, errors.New("expresión inválida, pila final no tiene un único árbol")
 	}
-	return stack[0]
+	return stack[0], nil
 }
 
-func GenerateDotFile(root *Node, fileName int) {
-	fileNameStr := strconv.Itoa(fileName)
-	file, err := os.Create("dotfiles/linea" + fileNameStr + ".dot")
+// PostfixToTree es BuildTree con DefaultTreeOptions; imprime el error y
+// devuelve nil si la expresión es inválida.
+func PostfixToTree(postfix string) *Node {
+	root, err := BuildTree(postfix, DefaultTreeOptions)
 	if err != nil {
-		fmt.Println("Error creando archivo .dot:", err)
-		return
+		fmt.Println("Error:", err)
+		return nil
 	}
-	defer file.Close()
+	return root
+}
+
+// WriteDot escribe el árbol en formato DOT. Cada nodo muestra su operador,
+// su subexpresión, si es anulable y sus primeras cadenas; los nodos
+// anulables se colorean.
+func WriteDot(w io.Writer, root *Node) error {
 	// Encabezado
-	fmt.Fprintln(file, "digraph SyntaxTree {")
-	fmt.Fprintln(file, "    node [shape=circle];")
+	fmt.Fprintln(w, "digraph SyntaxTree {")
+	fmt.Fprintln(w, "    node [shape=box, style=rounded, fontname=\"Helvetica\"];")
 
 	var writeNode func(n *Node)
 	writeNode = func(n *Node) {
 		if n == nil {
 			return
 		}
-		fmt.Fprintf(file, "    %d [label=\"%s\"];\n", n.ID, n.Label)
-		// Escribr nodo izquierdo
+		nullable, style := "no", ""
+		if n.Nullable {
+			nullable, style = "sí", ", style=\"rounded,filled\", fillcolor=\"#dbeafe\""
+		}
+		label := fmt.Sprintf("%s\\n%s\\nanulable: %s\\nL: %s",
+			dotEscape(n.Label), dotEscape(n.Expr), nullable, dotEscape(n.sampleText()))
+		fmt.Fprintf(w, "    %d [label=\"%s\"%s];\n", n.ID, label, style)
+		// Escribir nodo izquierdo
 		if n.Left != nil {
-			fmt.Fprintf(file, "    %d -> %d;\n", n.ID, n.Left.ID)
+			fmt.Fprintf(w, "    %d -> %d;\n", n.ID, n.Left.ID)
 			writeNode(n.Left)
 		}
 		if n.Right != nil {
-			fmt.Fprintf(file, "    %d -> %d;\n", n.ID, n.Right.ID)
+			fmt.Fprintf(w, "    %d -> %d;\n", n.ID, n.Right.ID)
 			writeNode(n.Right)
 		}
 	}
 	writeNode(root)
-	fmt.Fprintln(file, "}")
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// dotEscape escapa las comillas y barras invertidas de una etiqueta DOT.
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// sampleText lista las cadenas de ejemplo como "{ε, a, aa, …}".
+func (n *Node) sampleText() string {
+	if len(n.Samples) == 0 && !n.More {
+		return "∅"
+	}
+	parts := make([]string, len(n.Samples))
+	for i, s := range n.Samples {
+		if s == "" {
+			s = "ε"
+		}
+		parts[i] = s
+	}
+	if n.More {
+		parts = append(parts, "…")
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func GenerateDotFile(root *Node, fileName int) {
+	fileNameStr := strconv.Itoa(fileName)
+	file, err := os.Create("dotfiles/linea" + fileNameStr + ".dot")
+	if err != nil {
+		fmt.Println("Error creando archivo .dot:", err)
+		return
+	}
+	defer file.Close()
+	if err := WriteDot(file, root); err != nil {
+		fmt.Println("Error escribiendo archivo .dot:", err)
+		return
+	}
 
 	fmt.Println("Archivo Dot creado:", "linea"+fileNameStr+".dot")
 }
 
-func GeneratePNGFromDot(fileName int) {
+// GenerateImageFromDot ejecuta dot sobre dotfiles/lineaN.dot y guarda la
+// imagen en pngfiles/ o svgfiles/ según el formato ("png" o "svg").
+func GenerateImageFromDot(fileName int, format string) {
+	if format != "png" && format != "svg" {
+		fmt.Println("Error: formato de imagen desconocido:", format)
+		return
+	}
 	fileNameStr := strconv.Itoa(fileName)
 	dotFile := "dotfiles/linea" + fileNameStr + ".dot"
-	pngFile := "pngfiles/linea" + fileNameStr + ".png"
+	outDir := format + "files"
+	outFile := outDir + "/linea" + fileNameStr + "." + format
 
-	cmd := exec.Command("dot", "-Tpng", dotFile, "-o", pngFile)
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		fmt.Println("Error creando carpeta:", err)
+		return
+	}
+	cmd := exec.Command("dot", "-T"+format, dotFile, "-o", outFile)
 	err := cmd.Run()
 	if err != nil {
-		fmt.Println("Error generando PNG:", err)
+		fmt.Println("Error generando imagen:", err)
 		return
 	}
-	fmt.Println("Imagen PNG generada:", pngFile)
+	fmt.Println("Imagen generada:", outFile)
+}
+
+func GeneratePNGFromDot(fileName int) {
+	GenerateImageFromDot(fileName, "png")
 }
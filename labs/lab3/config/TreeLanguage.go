@@ -0,0 +1,137 @@
+package config
+
+import (
+	"math"
+	"sort"
+)
+
+// byLength guarda, para cada longitud l, las cadenas más pequeñas (en orden
+// alfabético) de longitud l de un lenguaje. Quedarse solo con las k primeras
+// de cada longitud basta para obtener las k primeras de una unión,
+// concatenación o estrella, porque todas las cadenas de una misma longitud
+// empiezan comparándose por su prefijo.
+type byLength [][]string
+
+// precedence devuelve qué tan fuerte liga un nodo al escribirlo en infix.
+func (n *Node) precedence() int {
+	switch n.Label {
+	case "|":
+		return 1
+	case ".":
+		return 2
+	case "*":
+		return 3
+	default:
+		return 4
+	}
+}
+
+// annotate calcula Expr, Nullable y Samples a partir de los hijos, que ya
+// están anotados.
+func (n *Node) annotate(opts TreeOptions) {
+	// se guarda una cadena más por longitud para saber si hay más ejemplos
+	k := opts.Samples + 1
+	wrap := func(c *Node, min int) string {
+		if c.precedence() < min {
+			return "(" + c.Expr + ")"
+		}
+		return c.Expr
+	}
+
+	switch n.Label {
+	case "|":
+		n.Expr = wrap(n.Left, 1) + "|" + wrap(n.Right, 2)
+		n.Nullable = n.Left.Nullable || n.Right.Nullable
+		n.longest = max(n.Left.longest, n.Right.longest)
+		n.lang = make(byLength, opts.MaxLength+1)
+		for l := range n.lang {
+			n.lang[l] = smallest(append(append([]string(nil), n.Left.lang[l]...), n.Right.lang[l]...), k)
+		}
+	case ".":
+		n.Expr = wrap(n.Left, 2) + wrap(n.Right, 3)
+		n.Nullable = n.Left.Nullable && n.Right.Nullable
+		n.longest = n.Left.longest + n.Right.longest
+		if n.Left.longest == math.MaxInt || n.Right.longest == math.MaxInt {
+			n.longest = math.MaxInt
+		}
+		n.lang = concatLang(n.Left.lang, n.Right.lang, k)
+	case "*":
+		n.Expr = wrap(n.Left, 3) + "*"
+		n.Nullable = true
+		if n.Left.longest > 0 {
+			n.longest = math.MaxInt
+		}
+		n.lang = make(byLength, opts.MaxLength+1)
+		n.lang[0] = []string{""}
+		for l := 1; l <= opts.MaxLength; l++ {
+			// una cadena de longitud l es un primer trozo no vacío de L(hijo)
+			// seguido de una cadena más corta de L(hijo*)
+			var out []string
+			for i := 1; i <= l; i++ {
+				for _, x := range n.Left.lang[i] {
+					for _, y := range n.lang[l-i] {
+						out = append(out, x+y)
+					}
+				}
+			}
+			n.lang[l] = smallest(out, k)
+		}
+	default:
+		n.Expr = n.Label
+		n.lang = make(byLength, opts.MaxLength+1)
+		if n.Label == "ε" {
+			n.Nullable = true
+			n.lang[0] = []string{""}
+		} else {
+			n.longest = 1
+			if opts.MaxLength >= 1 {
+				n.lang[1] = []string{n.Label}
+			}
+		}
+	}
+
+	// las cadenas más largas que MaxLength no se buscan, pero existen
+	n.Samples, n.More = nil, n.longest > opts.MaxLength
+	for _, set := range n.lang {
+		for _, s := range set {
+			if len(n.Samples) == opts.Samples {
+				n.More = true
+				return
+			}
+			n.Samples = append(n.Samples, s)
+		}
+	}
+}
+
+// concatLang calcula las cadenas de L1·L2 por longitud.
+func concatLang(a, b byLength, k int) byLength {
+	out := make(byLength, len(a))
+	for l := range out {
+		var set []string
+		for i := 0; i <= l; i++ {
+			for _, x := range a[i] {
+				for _, y := range b[l-i] {
+					set = append(set, x+y)
+				}
+			}
+		}
+		out[l] = smallest(set, k)
+	}
+	return out
+}
+
+// smallest ordena set, quita repetidos y devuelve a lo sumo k cadenas.
+func smallest(set []string, k int) []string {
+	sort.Strings(set)
+	var out []string
+	for _, s := range set {
+		if len(out) > 0 && s == out[len(out)-1] {
+			continue
+		}
+		out = append(out, s)
+		if len(out) == k {
+			break
+		}
+	}
+	return out
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"lab3/config"
 	"log"
@@ -9,7 +10,23 @@ import (
 )
 
 func main() {
-	file, err := os.Open("expressions1.txt")
+	inPath := flag.String("in", "expressions1.txt", "archivo con una expresión regular por línea")
+	img := flag.String("img", "png", "imagen a generar con Graphviz: png, svg o none")
+	samples := flag.Int("samples", config.DefaultTreeOptions.Samples, "cadenas de ejemplo por nodo")
+	maxLen := flag.Int("maxlen", config.DefaultTreeOptions.MaxLength, "longitud máxima de las cadenas de ejemplo")
+	flag.Parse()
+	if *img != "png" && *img != "svg" && *img != "none" {
+		log.Fatalf("-img debe ser png, svg o none, no %q", *img)
+	}
+	if *samples < 0 {
+		log.Fatalf("-samples no puede ser negativo, no %d", *samples)
+	}
+	if *maxLen < 0 {
+		log.Fatalf("-maxlen no puede ser negativo, no %d", *maxLen)
+	}
+	opts := config.TreeOptions{Samples: *samples, MaxLength: *maxLen}
+
+	file, err := os.Open(*inPath)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -23,9 +40,16 @@ func main() {
 		formatted := config.FormatRegex(expanded)
 		postfix := config.InfixToPostfix(formatted)
 		fmt.Println("Postfix: ", postfix)
-		root := config.PostfixToTree(postfix)
+		root, err := config.BuildTree(postfix, opts)
+		if err != nil {
+			fmt.Println("Error:", err)
+			i++
+			continue
+		}
 		config.GenerateDotFile(root, i)
-		config.GeneratePNGFromDot(i)
+		if *img != "none" {
+			config.GenerateImageFromDot(i, *img)
+		}
 		i++
 	}
 	if err := scanner.Err(); err != nil {
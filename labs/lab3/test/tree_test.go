@@ -0,0 +1,69 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"lab3/config"
+)
+
+func TestSampleAnnotation(t *testing.T) {
+	cases := []struct {
+		postfix  string
+		opts     config.TreeOptions
+		expr     string
+		nullable bool
+		samples  []string
+		more     bool
+	}{
+		{"a", config.DefaultTreeOptions, "a", false, []string{"a"}, false},
+		{"ε", config.DefaultTreeOptions, "ε", true, []string{""}, false},
+		{"ab|", config.DefaultTreeOptions, "a|b", false, []string{"a", "b"}, false},
+		{"abc|*.", config.DefaultTreeOptions, "a(b|c)*", false, []string{"a", "ab", "ac", "abb"}, true},
+		{"a*", config.DefaultTreeOptions, "a*", true, []string{"", "a", "aa", "aaa"}, true},
+		{"ab.*", config.TreeOptions{Samples: 10, MaxLength: 4}, "(ab)*", true, []string{"", "ab", "abab"}, true},
+		{"ab|c.", config.TreeOptions{Samples: 1, MaxLength: 6}, "(a|b)c", false, []string{"ac"}, true},
+		{"ab.", config.TreeOptions{Samples: 4, MaxLength: 1}, "ab", false, nil, true},
+		{"a*", config.TreeOptions{Samples: 0, MaxLength: 0}, "a*", true, nil, true},
+	}
+	for _, c := range cases {
+		root, err := config.BuildTree(c.postfix, c.opts)
+		if err != nil {
+			t.Fatalf("BuildTree(%q): %v", c.postfix, err)
+		}
+		if root.Expr != c.expr || root.Nullable != c.nullable {
+			t.Errorf("%s: Expr = %q, Nullable = %v, want %q, %v", c.postfix, root.Expr, root.Nullable, c.expr, c.nullable)
+		}
+		if !reflect.DeepEqual(root.Samples, c.samples) || root.More != c.more {
+			t.Errorf("%s with %+v: Samples = %q, More = %v, want %q, %v", c.expr, c.opts, root.Samples, root.More, c.samples, c.more)
+		}
+	}
+}
+
+func TestChildrenAnnotated(t *testing.T) {
+	root, err := config.BuildTree("abc|*.", config.DefaultTreeOptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	star := root.Right
+	if star.Expr != "(b|c)*" || !star.Nullable {
+		t.Errorf("star: Expr = %q, Nullable = %v", star.Expr, star.Nullable)
+	}
+	if want := []string{"", "b", "c", "bb"}; !reflect.DeepEqual(star.Samples, want) {
+		t.Errorf("star: Samples = %q, want %q", star.Samples, want)
+	}
+	if root.ID != 5 || root.Left.ID != 0 {
+		t.Errorf("IDs: root %d, left %d, want 5, 0", root.ID, root.Left.ID)
+	}
+}
+
+func TestInvalidTreeOptions(t *testing.T) {
+	for _, opts := range []config.TreeOptions{
+		{Samples: -1, MaxLength: 6},
+		{Samples: 4, MaxLength: -1},
+	} {
+		if _, err := config.BuildTree("ab.", opts); err == nil {
+			t.Errorf("BuildTree with %+v succeeded", opts)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Package charclass implements the character classes usable as regex
+// operands: the shorthands \d, \w and \s, their negations \D, \W and \S, and
+// Unicode properties such as \p{L}, \p{Greek} or \P{Lu}. A class is kept as
+// its canonical label (e.g. `\d`) on a single NFA transition and matched with
+// a predicate, so it never expands into one edge per rune.
+package charclass
+
+import (
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// lookupTable returns the Unicode range table for a category, script or
+// property name such as "L", "Greek" or "White_Space".
+func lookupTable(name string) (*unicode.RangeTable, bool) {
+	if t, ok := unicode.Categories[name]; ok {
+		return t, true
+	}
+	if t, ok := unicode.Scripts[name]; ok {
+		return t, true
+	}
+	if t, ok := unicode.Properties[name]; ok {
+		return t, true
+	}
+	return nil, false
+}
+
+// Parse reads a class written at chars[i], which must start with '\'.
+// It returns the canonical label and the index just past the class, or
+// ok=false if chars[i] does not start a known class.
+func Parse(chars []rune, i int) (label string, next int, ok bool) {
+	if i+1 >= len(chars) || chars[i] != '\\' {
+		return "", i, false
+	}
+	switch c := chars[i+1]; c {
+	case 'd', 'D', 'w', 'W', 's', 'S':
+		return `\` + string(c), i + 2, true
+	case 'p', 'P':
+		if i+2 >= len(chars) || chars[i+2] != '{' {
+			return "", i, false
+		}
+		for j := i + 3; j < len(chars); j++ {
+			if chars[j] == '}' {
+				name := string(chars[i+3 : j])
+				if _, ok := lookupTable(name); !ok {
+					return "", i, false
+				}
+				return string(chars[i : j+1]), j + 1, true
+			}
+		}
+	}
+	return "", i, false
+}
+
+// IsClass reports whether a transition label is a character class.
+func IsClass(label string) bool {
+	_, ok := predicate(label)
+	return ok
+}
+
+// predicate returns the membership test for a class label.
+func predicate(label string) (func(rune) bool, bool) {
+	if len(label) < 2 || label[0] != '\\' {
+		return nil, false
+	}
+	switch label {
+	case `\d`:
+		return unicode.IsDigit, true
+	case `\D`:
+		return func(r rune) bool { return !unicode.IsDigit(r) }, true
+	case `\w`:
+		return isWord, true
+	case `\W`:
+		return func(r rune) bool { return !isWord(r) }, true
+	case `\s`:
+		return unicode.IsSpace, true
+	case `\S`:
+		return func(r rune) bool { return !unicode.IsSpace(r) }, true
+	}
+	if len(label) < 5 || (label[1] != 'p' && label[1] != 'P') || label[2] != '{' || label[len(label)-1] != '}' {
+		return nil, false
+	}
+	t, ok := lookupTable(label[3 : len(label)-1])
+	if !ok {
+		return nil, false
+	}
+	if label[1] == 'P' {
+		return func(r rune) bool { return !unicode.Is(t, r) }, true
+	}
+	return func(r rune) bool { return unicode.Is(t, r) }, true
+}
+
+// isWord reports whether r is a letter, a digit or '_'.
+func isWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// Matches reports whether the class label matches sym. Only single-rune
+// symbols can match; token symbols such as "id" never do.
+func Matches(label, sym string) bool {
+	pred, ok := predicate(label)
+	if !ok {
+		return false
+	}
+	r, size := utf8.DecodeRuneInString(sym)
+	if size == 0 || size != len(sym) {
+		return false
+	}
+	return pred(r)
+}
+
+// overlaps caches Overlap results for pairs of classes, which are found by
+// scanning every rune.
+var overlaps = struct {
+	sync.Mutex
+	m map[[2]string]bool
+}{m: make(map[[2]string]bool)}
+
+// Overlap reports whether some symbol is matched by both labels, each of which
+// is a class or a plain symbol. Product constructions such as the quotients in
+// package ops use it to pair transitions.
+func Overlap(a, b string) bool {
+	if a == b {
+		return true
+	}
+	pa, aok := predicate(a)
+	pb, bok := predicate(b)
+	switch {
+	case !aok && !bok:
+		return false
+	case !aok:
+		return Matches(b, a)
+	case !bok:
+		return Matches(a, b)
+	}
+	if a > b {
+		a, b = b, a
+	}
+	key := [2]string{a, b}
+	overlaps.Lock()
+	defer overlaps.Unlock()
+	if v, ok := overlaps.m[key]; ok {
+		return v
+	}
+	v := false
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		if pa(r) && pb(r) {
+			v = true
+			break
+		}
+	}
+	overlaps.m[key] = v
+	return v
+}
+
+// Describe returns a readable name for a class label, used in DOT output,
+// e.g. "⟨digit⟩" for \d or "⟨not Greek⟩" for \P{Greek}.
+func Describe(label string) string {
+	switch label {
+	case `\d`:
+		return "⟨digit⟩"
+	case `\D`:
+		return "⟨not digit⟩"
+	case `\w`:
+		return "⟨word⟩"
+	case `\W`:
+		return "⟨not word⟩"
+	case `\s`:
+		return "⟨space⟩"
+	case `\S`:
+		return "⟨not space⟩"
+	}
+	if !IsClass(label) {
+		return label
+	}
+	name := label[3 : len(label)-1]
+	if label[1] == 'P' {
+		return "⟨not " + name + "⟩"
+	}
+	return "⟨" + name + "⟩"
+}
@@ -2,9 +2,12 @@
 package config
 
 import (
-	"fmt"
+	"io"
+	"os"
 	"strings"
 	"unicode"
+
+	"lab4/charclass"
 )
 
 // OperatorPrecedence defines precedence for regex operators.
@@ -26,6 +29,49 @@ func IsAlphanumeric(r rune) bool {
 	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == 'ε'
 }
 
+// ReadToken reads a bracketed token symbol such as "<id>" starting at chars[i].
+// It returns the name between the brackets and the index just past the closing
+// '>', or ok=false if chars[i] does not start a well-formed token.
+// Names may also contain ':', which transducers use for input:output pairs.
+// A name has at least two runes: "<a>" would share the transition label "a"
+// with the literal a, and "<ε>" would become an ε-transition.
+func ReadToken(chars []rune, i int) (name string, next int, ok bool) {
+	if i >= len(chars) || chars[i] != '<' {
+		return "", i, false
+	}
+	for j := i + 1; j < len(chars); j++ {
+		c := chars[j]
+		if c == '>' {
+			if j < i+3 {
+				return "", i, false
+			}
+			return string(chars[i+1 : j]), j + 1, true
+		}
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' && c != ':' {
+			return "", i, false
+		}
+	}
+	return "", i, false
+}
+
+// ReadEscape reads an escape starting at chars[i], which must be '\\'. Classes
+// such as "\\d" or "\\p{Greek}" yield their canonical label (see package
+// charclass); any other escaped rune, e.g. "\\*", yields that rune as a plain
+// symbol. It returns ok=false for a trailing '\\' or a malformed or unknown
+// "\\p{...}" class.
+func ReadEscape(chars []rune, i int) (sym string, next int, ok bool) {
+	if i+1 >= len(chars) || chars[i] != '\\' {
+		return "", i, false
+	}
+	if label, next, ok := charclass.Parse(chars, i); ok {
+		return label, next, true
+	}
+	if c := chars[i+1]; c != 'p' && c != 'P' {
+		return string(c), i + 2, true
+	}
+	return "", i, false
+}
+
 // ContainsRune checks if a slice contains a specific rune.
 func ContainsRune(slice []rune, r rune) bool {
 	for _, x := range slice {
@@ -38,9 +84,10 @@ func ContainsRune(slice []rune, r rune) bool {
 
 // shouldInsertConcat returns true if a '.' should be inserted between c1 and c2.
 func shouldInsertConcat(c1, c2 rune) bool {
-	// concat when: (symbol or '*' or ')') followed by (symbol or '(')
-	if (IsAlphanumeric(c1) || c1 == '*' || c1 == ')') &&
-		(IsAlphanumeric(c2) || c2 == '(') {
+	// concat when: (symbol or '*' or ')' or token '>') followed by
+	// (symbol or '(' or token '<' or escape '\\')
+	if (IsAlphanumeric(c1) || c1 == '*' || c1 == ')' || c1 == '>') &&
+		(IsAlphanumeric(c2) || c2 == '(' || c2 == '<' || c2 == '\\') {
 		return true
 	}
 	return false
@@ -54,12 +101,18 @@ func FormatRegex(regex string) string {
 
 	for i < len(chars) {
 		c1 := chars[i]
-		// preserve escapes
-		if c1 == '\\' && i+1 < len(chars) {
-			b.WriteRune(c1)
-			b.WriteRune(chars[i+1])
-			i += 2
-			if i < len(chars) && shouldInsertConcat(chars[i-1], chars[i]) {
+		// keep escapes, classes such as "\\d" and token symbols such as "<id>"
+		// whole; each is a single operand
+		next, ok := 0, false
+		if c1 == '\\' {
+			_, next, ok = ReadEscape(chars, i)
+		} else {
+			_, next, ok = ReadToken(chars, i)
+		}
+		if ok {
+			b.WriteString(string(chars[i:next]))
+			i = next
+			if i < len(chars) && shouldInsertConcat('>', chars[i]) {
 				b.WriteRune('.')
 			}
 			continue
@@ -87,10 +140,16 @@ func ExpandRegexExtensions(expr string) string {
 	for i := 0; i < len(in); i++ {
 		c := in[i]
 
-		// preserve escapes
-		if c == '\\' && i+1 < len(in) {
-			out = append(out, c, in[i+1])
-			i++
+		// preserve escapes and classes such as "\\p{Greek}"
+		if _, next, ok := ReadEscape(in, i); ok {
+			out = append(out, in[i:next]...)
+			i = next - 1
+			continue
+		}
+		// keep token symbols such as "<id>" whole
+		if _, next, ok := ReadToken(in, i); ok {
+			out = append(out, in[i:next]...)
+			i = next - 1
 			continue
 		}
 		// handle '+' and '?'
@@ -99,16 +158,18 @@ func ExpandRegexExtensions(expr string) string {
 			X := string(out[start:end])
 
 			// replace last operand with its expansion
-			// '+' -> X.X*
+			// '+' -> (X.X*)
 			// '?' -> (X|ε)
+			// both are grouped so a following '+', '?' or '*' applies to the whole expansion
 			tmp := make([]rune, 0, len(out))
 			tmp = append(tmp, out[:start]...)
 
 			if c == '+' {
+				tmp = append(tmp, '(')
 				tmp = append(tmp, []rune(X)...)
 				tmp = append(tmp, '.')
 				tmp = append(tmp, []rune(X)...)
-				tmp = append(tmp, '*')
+				tmp = append(tmp, '*', ')')
 			} else {
 				tmp = append(tmp, '(')
 				tmp = append(tmp, []rune(X)...)
@@ -125,13 +186,20 @@ func ExpandRegexExtensions(expr string) string {
 }
 
 // lastOperandBounds finds the start and end indices of the last operand in out.
-// An operand can be a single symbol, an escaped symbol, or a parenthesized group
+// An operand can be a single symbol, an escaped symbol, a class such as "\\d"
+// or "\\p{Greek}", a token symbol such as "<id>", or a parenthesized group
 func lastOperandBounds(out []rune) (int, int) {
 	if len(out) == 0 {
 		return 0, 0
 	}
 	j := len(out) - 1
 
+	// case 0: starred operand, e.g. "a*" or "(a|b)*", is taken as a whole
+	if out[j] == '*' && !(j > 0 && out[j-1] == '\\') {
+		start, _ := lastOperandBounds(out[:j])
+		return start, len(out)
+	}
+
 	// case 1: parenthesized group
 	if out[j] == ')' {
 		depth := 0
@@ -149,69 +217,130 @@ func lastOperandBounds(out []rune) (int, int) {
 		return 0, len(out) // fallback if unbalanced
 	}
 
-	// case 2: escaped rune
-	if j > 0 && out[j-1] == '\\' {
-		return j - 1, j + 1
+	// case 2: token symbol
+	if out[j] == '>' {
+		for k := j - 1; k >= 0; k-- {
+			if out[k] == '<' {
+				if _, next, ok := ReadToken(out, k); ok && next == len(out) {
+					return k, len(out)
+				}
+				break
+			}
+		}
+	}
+
+	// case 3: escaped rune or class; only "\\p{...}" spans more than two runes
+	for k := j - 1; k >= 0; k-- {
+		if out[k] == '\\' {
+			if _, next, ok := ReadEscape(out, k); ok && next == len(out) {
+				return k, len(out)
+			}
+			break
+		}
+		if out[j] != '}' {
+			break
+		}
 	}
 
-	// case 3: single rune
+	// case 4: single rune
 	return j, j + 1
 }
 
 // InfixToPostfix converts an infix regex expression to postfix notation using the Shunting Yard algorithm.
+// Each step is traced to stdout.
 func InfixToPostfix(rawRegex string) string {
-	expr := rawRegex
+	return InfixToPostfixTo(os.Stdout, rawRegex)
+}
+
+// InfixToPostfixTo is InfixToPostfix with the Shunting Yard trace written to w
+// instead of stdout; pass io.Discard to silence it.
+func InfixToPostfixTo(w io.Writer, rawRegex string) string {
+	postfix, steps := InfixToPostfixSteps(rawRegex)
+	WriteTrace(w, steps)
+	return postfix
+}
+
+// InfixToPostfixSteps converts an infix regex expression to postfix notation
+// like InfixToPostfix, printing nothing: the Shunting Yard steps are returned
+// as data instead, ready to be rendered with WriteTrace or WriteStepTable.
+func InfixToPostfixSteps(rawRegex string) (string, []Step) {
+	expr := []rune(rawRegex)
 	var output strings.Builder
 	var stack []rune
+	var steps []Step
+	record := func(kind StepKind, sym string, prec int) {
+		steps = append(steps, Step{Kind: kind, Symbol: sym, Prec: prec, Output: output.String(), Stack: string(stack)})
+	}
 
-	for _, c := range expr {
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
 		switch {
+		case c == '<':
+			// token symbols are operands too; copy "<name>" through unchanged
+			if _, next, ok := ReadToken(expr, i); ok {
+				output.WriteString(string(expr[i:next]))
+				record(StepOperand, string(expr[i:next]), 0)
+				i = next - 1
+				continue
+			}
+			output.WriteRune(c)
+
+		case c == '\\':
+			// so are escapes and classes such as "\\d" or "\\p{Greek}"
+			if _, next, ok := ReadEscape(expr, i); ok {
+				output.WriteString(string(expr[i:next]))
+				record(StepOperand, string(expr[i:next]), 0)
+				i = next - 1
+				continue
+			}
+			output.WriteRune(c)
+
 		case IsAlphanumeric(c):
-			fmt.Printf("Append operando '%c' → output = %s\n", c, output.String())
 			output.WriteRune(c)
+			record(StepOperand, string(c), 0)
 
 		case c == '(':
-			fmt.Printf("Push '(': stack = %q\n", stack)
 			stack = append(stack, c)
+			record(StepPush, "(", 0)
 
 		case c == ')':
-			fmt.Println("Encontrado ')', pop hasta '('")
+			record(StepClose, ")", 0)
 			for len(stack) > 0 && stack[len(stack)-1] != '(' {
 				top := stack[len(stack)-1]
 				stack = stack[:len(stack)-1]
 				output.WriteRune(top)
-				fmt.Printf("  Pop '%c' → output = %s\n", top, output.String())
+				record(StepPop, string(top), OperatorPrecedence[top])
 			}
 			if len(stack) > 0 {
-				fmt.Printf("  Pop '(': stack = %q\n", stack)
 				stack = stack[:len(stack)-1]
+				record(StepDiscard, "(", 0)
 			}
 
 		default:
 			precC := OperatorPrecedence[c]
-			fmt.Printf("Operador '%c' (precedencia %d) encontrado\n", c, precC)
+			record(StepOperator, string(c), precC)
 			for len(stack) > 0 {
 				top := stack[len(stack)-1]
 				precTop := OperatorPrecedence[top]
 				if precTop >= precC {
 					stack = stack[:len(stack)-1]
 					output.WriteRune(top)
-					fmt.Printf("  Pop '%c' (prec %d ≥ %d) → output = %s\n", top, precTop, precC, output.String())
+					record(StepPop, string(top), precTop)
 					continue
 				}
 				break
 			}
 			stack = append(stack, c)
-			fmt.Printf("Push '%c': stack = %q\n", c, stack)
+			record(StepPush, string(c), precC)
 		}
 	}
 
-	fmt.Println("Fin de input, vaciando pila:")
+	record(StepEnd, "", 0)
 	for len(stack) > 0 {
 		top := stack[len(stack)-1]
 		stack = stack[:len(stack)-1]
 		output.WriteRune(top)
-		fmt.Printf("  Pop '%c' → output = %s\n", top, output.String())
+		record(StepPop, string(top), OperatorPrecedence[top])
 	}
-	return output.String()
+	return output.String(), steps
 }
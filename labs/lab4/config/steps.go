@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// StepKind classifies one step of the Shunting Yard algorithm.
+type StepKind int
+
+const (
+	StepOperand  StepKind = iota // an operand is appended to the output
+	StepOperator                 // an operator is read, before any pops
+	StepPush                     // an operator or '(' is pushed on the stack
+	StepPop                      // an operator is popped to the output
+	StepClose                    // ')' is read; operators are popped down to '('
+	StepDiscard                  // the matching '(' is popped and dropped
+	StepEnd                      // end of input; the stack is emptied
+)
+
+// stepNames are the action names shown by WriteStepTable.
+var stepNames = map[StepKind]string{
+	StepOperand:  "operando",
+	StepOperator: "operador",
+	StepPush:     "push",
+	StepPop:      "pop",
+	StepClose:    "cierre",
+	StepDiscard:  "descarta (",
+	StepEnd:      "fin",
+}
+
+func (k StepKind) String() string { return stepNames[k] }
+
+// Step is one step of InfixToPostfixSteps, with the output and the operator
+// stack (bottom first) as they are after the step.
+type Step struct {
+	Kind   StepKind
+	Symbol string // operand or operator involved; empty for StepEnd
+	Prec   int    // precedence of Symbol when it is an operator
+	Output string
+	Stack  string
+}
+
+// WriteTrace writes steps in the format InfixToPostfix prints to stdout.
+func WriteTrace(w io.Writer, steps []Step) {
+	// pops that follow StepOperator are caused by that operator's precedence
+	byPrec, prec := false, 0
+	for _, st := range steps {
+		switch st.Kind {
+		case StepOperand:
+			before := strings.TrimSuffix(st.Output, st.Symbol)
+			if utf8.RuneCountInString(st.Symbol) == 1 {
+				fmt.Fprintf(w, "Append operando '%s' → output = %s\n", st.Symbol, before)
+			} else {
+				fmt.Fprintf(w, "Append operando %s → output = %s\n", st.Symbol, before)
+			}
+		case StepOperator:
+			byPrec, prec = true, st.Prec
+			fmt.Fprintf(w, "Operador '%s' (precedencia %d) encontrado\n", st.Symbol, st.Prec)
+		case StepPush:
+			if st.Symbol == "(" {
+				fmt.Fprintf(w, "Push '(': stack = %q\n", []rune(strings.TrimSuffix(st.Stack, "(")))
+			} else {
+				fmt.Fprintf(w, "Push '%s': stack = %q\n", st.Symbol, []rune(st.Stack))
+			}
+			byPrec = false
+		case StepPop:
+			if byPrec {
+				fmt.Fprintf(w, "  Pop '%s' (prec %d ≥ %d) → output = %s\n", st.Symbol, st.Prec, prec, st.Output)
+			} else {
+				fmt.Fprintf(w, "  Pop '%s' → output = %s\n", st.Symbol, st.Output)
+			}
+		case StepClose:
+			byPrec = false
+			fmt.Fprintln(w, "Encontrado ')', pop hasta '('")
+		case StepDiscard:
+			fmt.Fprintf(w, "  Pop '(': stack = %q\n", []rune(st.Stack+"("))
+		case StepEnd:
+			byPrec = false
+			fmt.Fprintln(w, "Fin de input, vaciando pila:")
+		}
+	}
+}
+
+// WriteStepTable writes steps as an aligned table with one row per step.
+func WriteStepTable(w io.Writer, steps []Step) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tacción\tsímbolo\tsalida\tpila")
+	for i, st := range steps {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", i+1, st.Kind, st.Symbol, st.Output, st.Stack)
+	}
+	return tw.Flush()
+}
@@ -1,3 +1,7 @@
 module lab4
 
 go 1.24.1
+
+require golang.org/x/term v0.34.0
+
+require golang.org/x/sys v0.35.0 // indirect
@@ -0,0 +1,168 @@
+// Package grammar converts a Thompson NFA into an equivalent right-linear
+// grammar, written in the "A -> a B | e" format read by projects/project2's
+// cmd/cyk, so both tools can be checked to agree on membership.
+package grammar
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"lab4/charclass"
+	"lab4/thompson"
+)
+
+// Production is a right-linear production Left -> Right. Right is either
+// empty (ε), a single non-terminal (from an ε-transition), or a terminal
+// followed by a non-terminal.
+type Production struct {
+	Left  string
+	Right []string
+}
+
+// Grammar is a right-linear grammar. Productions are grouped by their left
+// side, and the start symbol's productions come first.
+type Grammar struct {
+	Start       string
+	Productions []Production
+}
+
+// NonTerminal returns the non-terminal name used for the NFA state with the
+// given ID.
+func NonTerminal(id int) string {
+	return fmt.Sprintf("Q%d", id)
+}
+
+// FromNFA builds the right-linear grammar for the NFA: every transition
+// q --a--> p becomes Q -> a P, every ε-transition becomes Q -> P, and each
+// accept state gets Q -> e. An ε-free NFA yields a grammar without unit
+// productions.
+func FromNFA(n *thompson.NFA) (*Grammar, error) {
+	if n == nil || n.Start == nil {
+		return nil, fmt.Errorf("nil NFA")
+	}
+
+	// start state first, then the rest by ID for stable output
+	states := make([]*thompson.State, 0, len(n.States))
+	for _, s := range n.States {
+		if s != n.Start {
+			states = append(states, s)
+		}
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].ID < states[j].ID })
+	states = append([]*thompson.State{n.Start}, states...)
+
+	g := &Grammar{Start: NonTerminal(n.Start.ID)}
+	for _, s := range states {
+		left := NonTerminal(s.ID)
+
+		syms := make([]string, 0, len(s.Trans))
+		for sym := range s.Trans {
+			syms = append(syms, sym)
+		}
+		sort.Strings(syms)
+
+		for _, sym := range syms {
+			if sym != thompson.Epsilon && sym[0] >= 'A' && sym[0] <= 'Z' {
+				// cmd/cyk reads any symbol starting with A-Z as a non-terminal
+				return nil, fmt.Errorf("symbol %q would be read as a non-terminal by cyk", sym)
+			}
+			if charclass.IsClass(sym) {
+				// cyk compares terminals literally, it has no notion of classes
+				return nil, fmt.Errorf("class %s has no equivalent terminal in cyk", sym)
+			}
+			outs := append([]*thompson.State(nil), s.Trans[sym]...)
+			sort.Slice(outs, func(i, j int) bool { return outs[i].ID < outs[j].ID })
+			for _, t := range outs {
+				right := []string{NonTerminal(t.ID)}
+				if sym != thompson.Epsilon {
+					right = append([]string{sym}, right...)
+				}
+				g.Productions = append(g.Productions, Production{Left: left, Right: right})
+			}
+		}
+
+		if n.IsAccept(s) {
+			g.Productions = append(g.Productions, Production{Left: left})
+		}
+	}
+	return g, nil
+}
+
+// String renders the grammar one left side per line, alternatives joined
+// with " | " and ε written as "e".
+func (g *Grammar) String() string {
+	var b strings.Builder
+	for i := 0; i < len(g.Productions); {
+		left := g.Productions[i].Left
+		var alts []string
+		for ; i < len(g.Productions) && g.Productions[i].Left == left; i++ {
+			right := g.Productions[i].Right
+			if len(right) == 0 {
+				alts = append(alts, "e")
+				continue
+			}
+			alts = append(alts, strings.Join(right, " "))
+		}
+		fmt.Fprintf(&b, "%s -> %s\n", left, strings.Join(alts, " | "))
+	}
+	return b.String()
+}
+
+// nullable returns the non-terminals that derive ε.
+func (g *Grammar) nullable() map[string]bool {
+	null := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for _, p := range g.Productions {
+			if null[p.Left] {
+				continue
+			}
+			// right-linear: ε, or a unit production to a nullable non-terminal
+			if len(p.Right) == 0 || (len(p.Right) == 1 && null[p.Right[0]]) {
+				null[p.Left] = true
+				changed = true
+			}
+		}
+	}
+	return null
+}
+
+// NonEmpty returns a grammar for the same language minus the empty string:
+// the ε-productions are dropped and every Q -> a P with P nullable also gets
+// Q -> a.
+func (g *Grammar) NonEmpty() *Grammar {
+	null := g.nullable()
+	out := &Grammar{Start: g.Start}
+	for _, p := range g.Productions {
+		if len(p.Right) == 0 {
+			continue
+		}
+		out.Productions = append(out.Productions, p)
+		if len(p.Right) == 2 && null[p.Right[1]] {
+			out.Productions = append(out.Productions, Production{Left: p.Left, Right: p.Right[:1]})
+		}
+	}
+	return out
+}
+
+// WriteFile writes the grammar to path in cmd/cyk's grammar file format.
+// cyk's CNF conversion rejects a start symbol that derives ε (and cyk cannot
+// read an empty input anyway), so in that case the file holds NonEmpty.
+func WriteFile(g *Grammar, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fmt.Fprintln(f, "# Right-linear grammar generated from a Thompson NFA (lab4)")
+	fmt.Fprintf(f, "# Start symbol: %s\n", g.Start)
+	if g.nullable()[g.Start] {
+		fmt.Fprintln(f, "# The language contains ε, which cyk cannot represent: this grammar omits it")
+		g = g.NonEmpty()
+	}
+	_, err = fmt.Fprint(f, g.String())
+	return err
+}
@@ -1,23 +1,75 @@
 // Package graphviz provides functions to generate Graphviz DOT files and PNG images
-// from a Thompson NFA.
+// from a Thompson NFA or a transducer built on one.
 package graphviz
 
 import (
 	"fmt"
+	"io"
+	"lab4/charclass"
 	"lab4/thompson"
+	"lab4/transducer"
 	"os"
 	"os/exec"
 	"sort"
+	"strings"
+	"unicode/utf8"
 )
 
 // WriteDOT writes the NFA to a DOT file at the specified path.
 func WriteDOT(nfa *thompson.NFA, path string) error {
+	return writeDOT(nfa, path, symbolLabel, nil)
+}
+
+// EncodeDOT writes the NFA in DOT format to w.
+func EncodeDOT(w io.Writer, nfa *thompson.NFA) error {
+	return encodeDOT(w, nfa, symbolLabel, nil)
+}
+
+// WriteMealyDOT writes a Mealy transducer to a DOT file, labeling each
+// transition as "in/out".
+func WriteMealyDOT(m *transducer.Mealy, path string) error {
+	return writeDOT(m.NFA, path, func(label string) string {
+		in, out := transducer.SplitLabel(label)
+		return symbolLabel(in) + "/" + symbolLabel(out)
+	}, nil)
+}
+
+// WriteMooreDOT writes a Moore transducer to a DOT file, labeling each
+// transition with its input and each state as "q/out".
+func WriteMooreDOT(m *transducer.Moore, path string) error {
+	return writeDOT(m.NFA, path, symbolLabel, func(s *thompson.State) string {
+		return fmt.Sprintf("q%d/%s", s.ID, symbolLabel(m.OutputOf(s)))
+	})
+}
+
+// dotEscaper escapes the characters that are special inside a quoted DOT label.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// symbolLabel renders a transition symbol, naming classes such as \d as
+// ⟨digit⟩ and wrapping token symbols in <...>.
+func symbolLabel(label string) string {
+	if charclass.IsClass(label) {
+		return charclass.Describe(label)
+	}
+	if utf8.RuneCountInString(label) > 1 {
+		return "<" + label + ">" // token symbol
+	}
+	return dotEscaper.Replace(label)
+}
+
+// writeDOT creates the file at path and writes nfa to it with encodeDOT.
+func writeDOT(nfa *thompson.NFA, path string, edgeLabel func(string) string, stateLabel func(*thompson.State) string) error {
 	f, err := os.Create(path)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
+	return encodeDOT(f, nfa, edgeLabel, stateLabel)
+}
 
+// encodeDOT writes nfa to f using edgeLabel for transition labels and, if
+// non-nil, stateLabel for node labels.
+func encodeDOT(f io.Writer, nfa *thompson.NFA, edgeLabel func(string) string, stateLabel func(*thompson.State) string) error {
 	// header and graph settings
 	fmt.Fprintln(f, "digraph NFA {")
 	fmt.Fprintln(f, "  rankdir=LR;")
@@ -27,10 +79,7 @@ func WriteDOT(nfa *thompson.NFA, path string) error {
 	fmt.Fprintf(f, "  s [shape=point];\n")
 	fmt.Fprintf(f, "  s -> q%d;\n", nfa.Start.ID)
 
-	// accept as doublecircle node
-	fmt.Fprintf(f, "  q%d [shape=doublecircle];\n", nfa.Accept.ID)
-
-	// nodes (sorted by ID for consistency)
+	// nodes (sorted by ID for consistency), accept states as doublecircle
 	ids := make([]int, 0, len(nfa.States))
 	idToState := make(map[int]*thompson.State)
 	for _, s := range nfa.States {
@@ -39,34 +88,54 @@ func WriteDOT(nfa *thompson.NFA, path string) error {
 	}
 	sort.Ints(ids)
 	for _, id := range ids {
-		if id == nfa.Accept.ID {
-			continue // already declared with doublecircle
+		s := idToState[id]
+		var attrs []string
+		if nfa.IsAccept(s) {
+			attrs = append(attrs, "shape=doublecircle")
+		}
+		if stateLabel != nil {
+			attrs = append(attrs, fmt.Sprintf("label=\"%s\"", stateLabel(s)))
+		}
+		if len(attrs) == 0 {
+			fmt.Fprintf(f, "  q%d;\n", id)
+			continue
 		}
-		fmt.Fprintf(f, "  q%d;\n", id)
+		fmt.Fprintf(f, "  q%d [%s];\n", id, strings.Join(attrs, ", "))
 	}
 
 	// edges (sorted by from ID, label, to ID for consistency)
 	for _, id := range ids {
 		s := idToState[id]
-		for label, outs := range s.Trans {
-			lab := string(label)
-			if label == thompson.Epsilon {
-				lab = "ε"
-			}
+		labels := make([]string, 0, len(s.Trans))
+		for label := range s.Trans {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+		for _, label := range labels {
+			outs := append([]*thompson.State(nil), s.Trans[label]...)
+			sort.Slice(outs, func(i, j int) bool { return outs[i].ID < outs[j].ID })
 			for _, t := range outs {
-				fmt.Fprintf(f, "  q%d -> q%d [label=\"%s\"];\n", s.ID, t.ID, lab)
+				fmt.Fprintf(f, "  q%d -> q%d [label=\"%s\"];\n", s.ID, t.ID, edgeLabel(label))
 			}
 		}
 	}
 
-	fmt.Fprintln(f, "}")
-	return nil
+	_, err := fmt.Fprintln(f, "}")
+	return err
 }
 
 // GeneratePNGFromDot generates a PNG image from a DOT file using the 'dot' command.
-func GeneratePNGFromDot(dotPath, pngPath string) error {
-	cmd := exec.Command("dot", "-Tpng", dotPath, "-o", pngPath)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+// Whatever dot prints is captured instead of going straight to the terminal,
+// so concurrent renders do not interleave: on failure it is part of the
+// error, otherwise (warnings) it is copied to diag.
+func GeneratePNGFromDot(dotPath, pngPath string, diag io.Writer) error {
+	out, err := exec.Command("dot", "-Tpng", dotPath, "-o", pngPath).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	_, err = diag.Write(out)
+	return err
 }
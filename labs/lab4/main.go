@@ -4,31 +4,129 @@
 // build their NFAs using Thompson's construction, and generate DOT and PNG files
 // for visualization. It also simulates the NFA with a given string to check acceptance.
 // It supports regex extensions like Kleene star, union, concatenation, and more.
+// Lines can be processed in parallel with -workers; output keeps input order.
 package main
 
 import (
 	"bufio"
+	"bytes"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"lab4/config"
+	"lab4/grammar"
 	"lab4/graphviz"
 	"lab4/nfa"
+	"lab4/ops"
 	"lab4/regex"
+	"lab4/repl"
+	"lab4/server"
 	"lab4/thompson"
+	"lab4/transducer"
 )
 
+// options holds the command-line settings shared by every input line.
+type options struct {
+	dotDir, pngDir, cfgDir    string
+	tokens, noEps, transducer bool
+	png                       pngPool
+}
+
+// pngPool bounds how many Graphviz processes run at the same time.
+type pngPool chan struct{}
+
+// render runs 'dot' once a slot in the pool is free; its warnings go to diag.
+func (p pngPool) render(dotPath, pngPath string, diag io.Writer) error {
+	p <- struct{}{}
+	defer func() { <-p }()
+	return graphviz.GeneratePNGFromDot(dotPath, pngPath, diag)
+}
+
+// lineOutput collects everything printed while processing one input line, so
+// lines can be processed concurrently and still be printed in input order.
+type lineOutput struct {
+	out  bytes.Buffer // what the sequential version printed to stdout
+	errs bytes.Buffer // what it logged to stderr
+	log  *log.Logger
+}
+
+// newLineOutput returns an empty lineOutput whose logger matches the default one.
+func newLineOutput() *lineOutput {
+	o := &lineOutput{}
+	o.log = log.New(&o.errs, "", log.LstdFlags)
+	return o
+}
+
+// job is one non-empty, non-comment line of the input file.
+type job struct {
+	lineNo int
+	raw    string
+}
+
 func main() {
 	// Command-line flags for input and output directories
 	inPath := flag.String("in", "input.txt", "path to input file")
 	dotDir := flag.String("dotout", "dotout", "output directory for DOT files")
 	pngDir := flag.String("pngout", "pngout", "output directory for PNG files")
+	tokens := flag.Bool("tokens", false, "token-alphabet mode: w is a whitespace-separated sequence of symbols such as <id>")
+	noEps := flag.Bool("noeps", false, "also eliminate ε-transitions: print the ε-closure table and save the ε-free NFA")
+	transducerMode := flag.Bool("transducer", false, "transducer mode: regex operands are input:output pairs such as a:x; print every output for w")
+	cfgDir := flag.String("cfgout", "", "output directory for right-linear grammar files (empty to skip)")
+	mode := flag.String("mode", "batch", "batch (process -in) | repl (interactive) | serve (HTTP JSON service) | stream (match -regex against -streamin)")
+	streamRegex := flag.String("regex", "", "regex to match (when -mode=stream)")
+	streamIn := flag.String("streamin", "-", "file to stream through the matcher, - for stdin (when -mode=stream)")
+	addr := flag.String("addr", "localhost:8080", "listen address (when -mode=serve)")
+	workers := flag.Int("workers", 1, "number of lines processed in parallel; also bounds concurrent PNG generation")
 	flag.Parse()
 
+	if *mode == "repl" {
+		if err := repl.Run(os.Stdin, os.Stdout, &repl.Session{Tokens: *tokens}); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if *mode == "serve" {
+		srv := &http.Server{
+			Addr:              *addr,
+			Handler:           server.NewHandler(server.DefaultLimits),
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       10 * time.Second,
+			WriteTimeout:      10 * time.Second,
+		}
+		log.Printf("serving POST /compile and POST /match on http://%s", *addr)
+		log.Fatal(srv.ListenAndServe())
+	}
+	if *mode == "stream" {
+		if err := runStream(*streamRegex, *streamIn, *tokens); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if *mode != "batch" {
+		log.Fatalf("unknown -mode %q, use batch, repl, serve or stream", *mode)
+	}
+
+	if *workers < 1 {
+		log.Fatalf("-workers must be at least 1, got %d", *workers)
+	}
+	opts := &options{
+		dotDir:     *dotDir,
+		pngDir:     *pngDir,
+		cfgDir:     *cfgDir,
+		tokens:     *tokens,
+		noEps:      *noEps,
+		transducer: *transducerMode,
+		png:        make(pngPool, *workers),
+	}
+
 	f, err := os.Open(*inPath)
 	if err != nil {
 		log.Fatalf("cannot open input file: %v", err)
@@ -37,6 +135,7 @@ func main() {
 
 	sc := bufio.NewScanner(f)
 	lineNo := 0
+	var jobs []job
 
 	for sc.Scan() {
 		lineNo++
@@ -44,75 +143,363 @@ func main() {
 		if raw == "" || strings.HasPrefix(raw, "#") {
 			continue
 		}
+		jobs = append(jobs, job{lineNo: lineNo, raw: raw})
+	}
 
-		// Enforce "regex;w"
-		parts := strings.SplitN(raw, ";", 2)
-		if len(parts) != 2 {
-			log.Printf("Line %d: invalid format. Expected 'regex;w'. Got: %q\n", lineNo, raw)
-			continue
-		}
-		r := strings.TrimSpace(parts[0])
-		w := strings.TrimSpace(parts[1])
-		if r == "" { // Check if regex is empty
-			log.Printf("Line %d: empty regex before ';'\n", lineNo)
-			continue
+	if err := sc.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Workers fill results[i] and close done[i]; the main goroutine prints the
+	// results in input order as soon as each one is ready.
+	results := make([]*lineOutput, len(jobs))
+	done := make([]chan struct{}, len(jobs))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range *workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				o := newLineOutput()
+				processLine(jobs[i].lineNo, jobs[i].raw, opts, o)
+				results[i] = o
+				close(done[i])
+			}
+		}()
+	}
+	go func() {
+		for i := range jobs {
+			next <- i
 		}
-		if w == "" { // Check if w is empty
-			log.Printf("Line %d: empty w after ';'\n", lineNo)
-			continue
+		close(next)
+	}()
+
+	for i := range jobs {
+		<-done[i]
+		os.Stdout.Write(results[i].out.Bytes())
+		os.Stderr.Write(results[i].errs.Bytes())
+		results[i] = nil
+	}
+	wg.Wait()
+}
+
+// processLine runs the whole pipeline for one "regex;w" line, writing its
+// report to o. A failure, even a panic, only affects this line.
+func processLine(lineNo int, raw string, opts *options, o *lineOutput) {
+	defer func() {
+		if p := recover(); p != nil {
+			o.log.Printf("Line %d: internal error: %v\n", lineNo, p)
 		}
+	}()
+	out := &o.out
+
+	// Enforce "regex;w" or "regex;w;operation"; a ';' inside w is kept
+	// unless what follows it names an operation
+	r, w, op, ok := ops.SplitLine(raw)
+	if !ok {
+		o.log.Printf("Line %d: invalid format. Expected 'regex;w'. Got: %q\n", lineNo, raw)
+		return
+	}
+	if r == "" { // Check if regex is empty
+		o.log.Printf("Line %d: empty regex before ';'\n", lineNo)
+		return
+	}
+	if w == "" { // Check if w is empty
+		o.log.Printf("Line %d: empty w after ';'\n", lineNo)
+		return
+	}
+
+	// Transducers have their own pipeline and output
+	if opts.transducer {
+		runTransducer(lineNo, r, w, opts, o)
+		return
+	}
 
-		// Expand and format the regex
-		// This handles extensions like Kleene star, union, concatenation, etc.
-		// It also formats the regex to a standard form.
-		// The regex is expected to be in infix notation.
-		expanded := config.ExpandRegexExtensions(r)
-		formatted := config.FormatRegex(expanded)
-		postfix := config.InfixToPostfix(formatted)
+	// Expand and format the regex
+	// This handles extensions like Kleene star, union, concatenation, etc.
+	// It also formats the regex to a standard form.
+	// The regex is expected to be in infix notation.
+	expanded := config.ExpandRegexExtensions(r)
+	formatted := config.FormatRegex(expanded)
+	postfix := config.InfixToPostfixTo(out, formatted)
 
-		fmt.Printf("Line %d\n", lineNo)
-		fmt.Printf("  raw       : %s\n", r)
-		fmt.Printf("  expanded  : %s\n", expanded)
-		fmt.Printf("  formatted : %s\n", formatted)
-		fmt.Printf("  postfix   : %s\n", postfix)
+	fmt.Fprintf(out, "Line %d\n", lineNo)
+	fmt.Fprintf(out, "  raw       : %s\n", r)
+	fmt.Fprintf(out, "  expanded  : %s\n", expanded)
+	fmt.Fprintf(out, "  formatted : %s\n", formatted)
+	fmt.Fprintf(out, "  postfix   : %s\n", postfix)
 
-		// Build AST from postfix regex
-		ast, err := regex.BuildAST(postfix)
+	// Build AST from postfix regex
+	ast, err := regex.BuildAST(postfix)
+	if err != nil {
+		o.log.Printf("  AST error: %v\n\n", err)
+		return
+	}
+
+	// Build NFA using Thompson's construction
+	nfaObj, err := thompson.Build(ast)
+	if err != nil {
+		o.log.Printf("  Thompson error: %v\n\n", err)
+		return
+	}
+
+	// Save DOT and PNG
+	dotPath := filepath.Join(opts.dotDir, fmt.Sprintf("nfa_%03d.dot", lineNo))
+	pngPath := filepath.Join(opts.pngDir, fmt.Sprintf("nfa_%03d.png", lineNo))
+
+	if err := graphviz.WriteDOT(nfaObj, dotPath); err != nil {
+		o.log.Printf("  DOT error: %v\n\n", err)
+		return
+	}
+	fmt.Fprintf(out, "  DOT saved: %s\n", dotPath)
+	renderPNG(dotPath, pngPath, opts, o)
+
+	// Optionally apply a language operation; the rest of the pipeline
+	// then works on the resulting NFA
+	if op != "" {
+		result, err := applyOperation(op, nfaObj)
 		if err != nil {
-			log.Printf("  AST error: %v\n\n", err)
-			continue
+			o.log.Printf("  Operation error: %v\n\n", err)
+			return
 		}
+		nfaObj = result
+		fmt.Fprintf(out, "  operation : %s (%d states)\n", op, len(nfaObj.States))
+		saveNFA(nfaObj,
+			filepath.Join(opts.dotDir, fmt.Sprintf("nfa_%03d_op.dot", lineNo)),
+			filepath.Join(opts.pngDir, fmt.Sprintf("nfa_%03d_op.png", lineNo)), opts, o)
+	}
 
-		// Build NFA using Thompson's construction
-		nfaObj, err := thompson.Build(ast)
+	// Optionally remove ε-transitions and save the ε-free NFA
+	var noEpsNFA *thompson.NFA
+	if opts.noEps {
+		fmt.Fprintln(out, "  ε-closure table:")
+		thompson.WriteClosureTable(out, nfaObj)
+		noEpsNFA = thompson.EliminateEpsilon(nfaObj)
+		fmt.Fprintf(out, "  ε-free NFA: %d states (was %d), %d accept states\n",
+			len(noEpsNFA.States), len(nfaObj.States), len(noEpsNFA.Accepts))
+		saveNFA(noEpsNFA,
+			filepath.Join(opts.dotDir, fmt.Sprintf("nfa_%03d_noeps.dot", lineNo)),
+			filepath.Join(opts.pngDir, fmt.Sprintf("nfa_%03d_noeps.png", lineNo)), opts, o)
+	}
+
+	// Optionally save the equivalent right-linear grammar for cmd/cyk,
+	// from the ε-free NFA when available so it has no unit productions
+	if opts.cfgDir != "" {
+		cfgPath := filepath.Join(opts.cfgDir, fmt.Sprintf("grammar_%03d.txt", lineNo))
+		src := nfaObj
+		if noEpsNFA != nil {
+			src = noEpsNFA
+		}
+		g, err := grammar.FromNFA(src)
+		if err == nil {
+			err = grammar.WriteFile(g, cfgPath)
+		}
 		if err != nil {
-			log.Printf("  Thompson error: %v\n\n", err)
-			continue
+			o.log.Printf("  Grammar error: %v\n", err)
+		} else {
+			fmt.Fprintf(out, "  Grammar saved: %s\n", cfgPath)
 		}
+	}
 
-		// Save DOT and PNG
-		dotPath := filepath.Join(*dotDir, fmt.Sprintf("nfa_%03d.dot", lineNo))
-		pngPath := filepath.Join(*pngDir, fmt.Sprintf("nfa_%03d.png", lineNo))
+	// Simulate NFA with the string w
+	// In token-alphabet mode each whitespace-separated word of w is one symbol
+	var accepted bool
+	if opts.tokens {
+		symbols := strings.Fields(w)
+		fmt.Fprintf(out, "  tokens    : %v\n", symbols)
+		accepted = nfa.SimulateTokens(nfaObj, symbols)
+	} else {
+		accepted = nfa.Simulate(nfaObj, w)
+	}
+	ans := map[bool]string{true: "sí", false: "no"}[accepted]
+	lang := "L(r)"
+	if op != "" {
+		lang = "op(L(r))"
+	}
+	fmt.Fprintf(out, "  w ∈ %s? %s   (w = %q)\n", lang, ans, w)
 
-		if err := graphviz.WriteDOT(nfaObj, dotPath); err != nil {
-			log.Printf("  DOT error: %v\n\n", err)
-			continue
+	if noEpsNFA != nil {
+		var ok bool
+		if opts.tokens {
+			ok = nfa.SimulateTokens(noEpsNFA, strings.Fields(w))
+		} else {
+			ok = nfa.Simulate(noEpsNFA, w)
 		}
-		fmt.Printf("  DOT saved: %s\n", dotPath)
+		fmt.Fprintf(out, "  ε-free NFA agrees? %v\n", ok == accepted)
+	}
+	fmt.Fprintln(out)
+}
 
-		if err := graphviz.GeneratePNGFromDot(dotPath, pngPath); err != nil {
-			log.Printf("  PNG error (is Graphviz installed?): %v\n\n", err)
-		} else {
-			fmt.Printf("  PNG saved: %s\n", pngPath)
+// saveNFA writes n as a DOT file and renders it to PNG, logging any failure.
+func saveNFA(n *thompson.NFA, dotPath, pngPath string, opts *options, o *lineOutput) {
+	if err := graphviz.WriteDOT(n, dotPath); err != nil {
+		o.log.Printf("  DOT error: %v\n", err)
+		return
+	}
+	fmt.Fprintf(&o.out, "  DOT saved: %s\n", dotPath)
+	renderPNG(dotPath, pngPath, opts, o)
+}
+
+// renderPNG renders a DOT file to PNG through the bounded pool, logging any failure.
+func renderPNG(dotPath, pngPath string, opts *options, o *lineOutput) {
+	if err := opts.png.render(dotPath, pngPath, &o.errs); err != nil {
+		o.log.Printf("  PNG error (is Graphviz installed?): %v\n", err)
+		return
+	}
+	fmt.Fprintf(&o.out, "  PNG saved: %s\n", pngPath)
+}
+
+// runTransducer builds the Mealy transducer for r and its Moore equivalent,
+// saves both as DOT/PNG and prints every output each one produces for w.
+func runTransducer(lineNo int, r, w string, opts *options, o *lineOutput) {
+	out := &o.out
+	fmt.Fprintf(out, "Line %d\n", lineNo)
+	fmt.Fprintf(out, "  raw       : %s\n", r)
+
+	mealy, err := transducer.Build(r)
+	if err != nil {
+		o.log.Printf("  Transducer error: %v\n\n", err)
+		return
+	}
+
+	input, sep := transducer.Symbols(w), ""
+	if opts.tokens {
+		input, sep = strings.Fields(w), " "
+	}
+	show := func(outs [][]string) string {
+		if len(outs) == 0 {
+			return "w rejected, no output"
+		}
+		parts := make([]string, len(outs))
+		for i, o := range outs {
+			parts[i] = fmt.Sprintf("%q", strings.Join(o, sep))
 		}
+		return strings.Join(parts, ", ")
+	}
 
-		// Simulate NFA with the string w
-		accepted := nfa.Simulate(nfaObj, w)
-		ans := map[bool]string{true: "sí", false: "no"}[accepted]
-		fmt.Printf("  w ∈ L(r)? %s   (w = %q)\n\n", ans, w)
+	dotPath := filepath.Join(opts.dotDir, fmt.Sprintf("mealy_%03d.dot", lineNo))
+	if err := graphviz.WriteMealyDOT(mealy, dotPath); err != nil {
+		o.log.Printf("  DOT error: %v\n", err)
+	} else {
+		fmt.Fprintf(out, "  DOT saved: %s\n", dotPath)
+		renderPNG(dotPath, filepath.Join(opts.pngDir, fmt.Sprintf("mealy_%03d.png", lineNo)), opts, o)
+	}
+	if outs, err := mealy.Translate(input); err != nil {
+		o.log.Printf("  Mealy error: %v\n", err)
+	} else {
+		fmt.Fprintf(out, "  Mealy outputs for %q: %s\n", w, show(outs))
 	}
 
-	if err := sc.Err(); err != nil {
-		log.Fatal(err)
+	moore, err := mealy.ToMoore()
+	if err != nil {
+		o.log.Printf("  Moore error: %v\n\n", err)
+		return
+	}
+	dotPath = filepath.Join(opts.dotDir, fmt.Sprintf("moore_%03d.dot", lineNo))
+	if err := graphviz.WriteMooreDOT(moore, dotPath); err != nil {
+		o.log.Printf("  DOT error: %v\n", err)
+	} else {
+		fmt.Fprintf(out, "  DOT saved: %s\n", dotPath)
+		renderPNG(dotPath, filepath.Join(opts.pngDir, fmt.Sprintf("moore_%03d.png", lineNo)), opts, o)
+	}
+	if outs, err := moore.Translate(input); err != nil {
+		o.log.Printf("  Moore error: %v\n", err)
+	} else {
+		fmt.Fprintf(out, "  Moore outputs for %q: %s\n", w, show(outs))
+	}
+	fmt.Fprintln(out)
+}
+
+// runStream feeds a file or stdin through a streaming matcher for r, so the
+// input is never held in memory. Every byte counts, including newlines.
+func runStream(r, path string, tokens bool) error {
+	if strings.TrimSpace(r) == "" {
+		return fmt.Errorf("missing -regex")
+	}
+	if tokens {
+		return fmt.Errorf("-mode=stream reads runes; -tokens is not supported")
+	}
+	n, err := compileRegex(r)
+	if err != nil {
+		return fmt.Errorf("regex %q: %w", r, err)
+	}
+
+	in := os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	m := nfa.NewMatcher(n)
+	start := time.Now()
+	if _, err := io.Copy(m, in); err != nil {
+		return err
+	}
+	ans := map[bool]string{true: "sí", false: "no"}[m.Accepting()]
+	fmt.Printf("w ∈ L(%s)? %s   (%d bytes in %v)\n", r, ans, m.BytesWritten(), time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// compileRegex runs the full pipeline (expand, format, postfix, AST,
+// Thompson) on r without tracing. It is used for the regex argument of
+// quotient operations and for -mode=stream.
+func compileRegex(r string) (*thompson.NFA, error) {
+	postfix := config.InfixToPostfixTo(io.Discard, config.FormatRegex(config.ExpandRegexExtensions(r)))
+	ast, err := regex.BuildAST(postfix)
+	if err != nil {
+		return nil, err
+	}
+	return thompson.Build(ast)
+}
+
+// applyOperation applies the language operation named in the third field of
+// an input line to n. Supported operations:
+//
+//	reverse          reversal of L(r)
+//	prefix           prefix closure of L(r)
+//	suffix           suffix closure of L(r)
+//	rquot:<regex>    right quotient L(r) / L(regex)
+//	lquot:<regex>    left quotient L(regex) \ L(r)
+//	hom:a->xy,b->ε   image of L(r) under the homomorphism
+func applyOperation(spec string, n *thompson.NFA) (*thompson.NFA, error) {
+	name, arg, _ := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "reverse":
+		return ops.Reverse(n), nil
+	case "prefix":
+		return ops.Prefix(n), nil
+	case "suffix":
+		return ops.Suffix(n), nil
+	case "rquot", "lquot":
+		if arg == "" {
+			return nil, fmt.Errorf("%s needs a regex, e.g. %s:ab*", name, name)
+		}
+		other, err := compileRegex(arg)
+		if err != nil {
+			return nil, fmt.Errorf("quotient regex %q: %w", arg, err)
+		}
+		if name == "rquot" {
+			return ops.RightQuotient(n, other), nil
+		}
+		return ops.LeftQuotient(n, other), nil
+	case "hom":
+		h, err := ops.ParseHomomorphism(arg)
+		if err != nil {
+			return nil, err
+		}
+		return h.Apply(n), nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q", name)
 	}
 }
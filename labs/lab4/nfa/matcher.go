@@ -0,0 +1,151 @@
+package nfa
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"lab4/thompson"
+)
+
+// maxCachedSets bounds the number of state sets a Matcher remembers; when the
+// cache is full it is cleared and rebuilt on demand.
+const maxCachedSets = 4096
+
+// dstate is an ε-closed set of NFA states with its outgoing moves cached,
+// i.e. one state of the DFA built lazily by the subset construction.
+type dstate struct {
+	set    stateSet
+	accept bool
+	next   map[string]*dstate
+}
+
+// Matcher simulates an NFA over input fed incrementally through Write, so
+// arbitrarily large inputs (files, pipes) never need to be held in memory.
+// Each rune of the input is one symbol, exactly as in Simulate. Visited state
+// sets are cached, so long inputs run at DFA speed after a warm-up.
+type Matcher struct {
+	nfa     *thompson.NFA
+	start   *dstate
+	current *dstate
+	cache   map[string]*dstate
+	pending []byte // incomplete UTF-8 sequence left over from the last Write
+	n       int64  // bytes written so far
+}
+
+// NewMatcher compiles n into a Matcher positioned at the start of the input.
+func NewMatcher(n *thompson.NFA) *Matcher {
+	m := &Matcher{nfa: n, cache: make(map[string]*dstate)}
+	start := make(stateSet)
+	add(start, n.Start)
+	m.start = m.intern(epsilonClosure(start))
+	m.current = m.start
+	return m
+}
+
+// setKey returns a canonical key for a set of states.
+func setKey(set stateSet) string {
+	ids := make([]int, 0, len(set))
+	for s := range set {
+		ids = append(ids, s.ID)
+	}
+	sort.Ints(ids)
+	var b strings.Builder
+	for _, id := range ids {
+		b.WriteString(strconv.Itoa(id))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+// intern returns the cached dstate for set, creating it if needed.
+func (m *Matcher) intern(set stateSet) *dstate {
+	key := setKey(set)
+	if d, ok := m.cache[key]; ok {
+		return d
+	}
+	if len(m.cache) >= maxCachedSets {
+		// drop every cached set; the start set is copied without its moves
+		// so the old sets become garbage
+		m.cache = make(map[string]*dstate)
+		m.start = &dstate{set: m.start.set, accept: m.start.accept, next: make(map[string]*dstate)}
+		m.cache[setKey(m.start.set)] = m.start
+		if d, ok := m.cache[key]; ok {
+			return d
+		}
+	}
+	d := &dstate{set: set, next: make(map[string]*dstate)}
+	for s := range set {
+		if m.nfa.IsAccept(s) {
+			d.accept = true
+			break
+		}
+	}
+	m.cache[key] = d
+	return d
+}
+
+// step consumes one symbol.
+func (m *Matcher) step(sym string) {
+	d := m.current
+	nxt, ok := d.next[sym]
+	if !ok {
+		nxt = m.intern(epsilonClosure(move(d.set, sym)))
+		d.next[sym] = nxt
+	}
+	m.current = nxt
+}
+
+// Write feeds p to the automaton. UTF-8 sequences may be split across calls;
+// the incomplete tail of p is kept until the next Write completes it. Invalid
+// bytes are read as utf8.RuneError, like Simulate does. Write never fails,
+// so a Matcher can be the destination of io.Copy.
+func (m *Matcher) Write(p []byte) (int, error) {
+	m.n += int64(len(p))
+	buf := p
+	if len(m.pending) > 0 {
+		buf = append(m.pending, p...)
+		m.pending = nil
+	}
+	for len(buf) > 0 {
+		if !utf8.FullRune(buf) {
+			m.pending = append([]byte(nil), buf...)
+			break
+		}
+		r, size := utf8.DecodeRune(buf)
+		buf = buf[size:]
+		m.step(string(r))
+	}
+	return len(p), nil
+}
+
+// WriteString is Write for a string.
+func (m *Matcher) WriteString(s string) (int, error) {
+	return m.Write([]byte(s))
+}
+
+// Accepting reports whether the input written so far is accepted. It is
+// false while a UTF-8 sequence is incomplete, since the input so far does
+// not end on a symbol boundary.
+func (m *Matcher) Accepting() bool {
+	return len(m.pending) == 0 && m.current.accept
+}
+
+// Dead reports whether no continuation of the input can be accepted any
+// more, so a caller may stop reading early.
+func (m *Matcher) Dead() bool {
+	return len(m.current.set) == 0
+}
+
+// BytesWritten returns the number of bytes written since the last Reset.
+func (m *Matcher) BytesWritten() int64 {
+	return m.n
+}
+
+// Reset rewinds the Matcher to the start of a new input, keeping its cache.
+func (m *Matcher) Reset() {
+	m.current = m.start
+	m.pending = nil
+	m.n = 0
+}
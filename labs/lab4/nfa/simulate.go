@@ -2,6 +2,7 @@
 package nfa
 
 import (
+	"lab4/charclass"
 	"lab4/thompson"
 	"unicode/utf8"
 )
@@ -38,32 +39,53 @@ func epsilonClosure(start stateSet) stateSet {
 	return seen
 }
 
-// move computes the set of states reachable from 'from' on input 'sym'.
-func move(from stateSet, sym rune) stateSet {
+// move computes the set of states reachable from 'from' on input 'sym',
+// following both transitions labeled sym and class transitions such as \d
+// whose class contains sym.
+func move(from stateSet, sym string) stateSet {
 	out := make(stateSet)
 	for s := range from {
-		for _, nxt := range s.Trans[sym] {
-			add(out, nxt)
+		for label, nexts := range s.Trans {
+			if label != sym && !charclass.Matches(label, sym) {
+				continue
+			}
+			for _, nxt := range nexts {
+				add(out, nxt)
+			}
 		}
 	}
 	return out
 }
 
 // Simulate returns true if input is accepted by the NFA.
+// Each rune of input is one symbol.
 func Simulate(nfa *thompson.NFA, input string) bool {
-	current := make(stateSet)
-	add(current, nfa.Start)
-	current = epsilonClosure(current)
-
+	symbols := make([]string, 0, len(input))
 	// iterate runes (supports UTF-8, including '0','1','a','b', etc.)
 	for len(input) > 0 {
 		r, size := utf8.DecodeRuneInString(input)
 		input = input[size:]
+		symbols = append(symbols, string(r))
+	}
+	return SimulateTokens(nfa, symbols)
+}
 
-		next := move(current, r)
+// SimulateTokens returns true if the symbol sequence is accepted by the NFA.
+// It is used in token-alphabet mode, where each symbol is a name such as "id".
+func SimulateTokens(nfa *thompson.NFA, symbols []string) bool {
+	current := make(stateSet)
+	add(current, nfa.Start)
+	current = epsilonClosure(current)
+
+	for _, sym := range symbols {
+		next := move(current, sym)
 		current = epsilonClosure(next)
 	}
 
-	_, ok := current[nfa.Accept]
-	return ok
+	for s := range current {
+		if nfa.IsAccept(s) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,432 @@
+// Package ops implements closure-property operations over Thompson NFAs:
+// reversal, prefix and suffix closures, left and right quotients, and string
+// homomorphisms. Every operation returns a new NFA and leaves its inputs
+// unchanged, so the result can be simulated and drawn like any other NFA.
+package ops
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"lab4/charclass"
+	"lab4/config"
+	"lab4/thompson"
+)
+
+// copier clones NFA states with fresh IDs and hands out new ones.
+type copier struct {
+	next   int
+	copies map[*thompson.State]*thompson.State
+}
+
+// newCopier returns a copier that numbers states from 0.
+func newCopier() *copier {
+	return &copier{copies: make(map[*thompson.State]*thompson.State)}
+}
+
+// newState creates a state with the next free ID.
+func (c *copier) newState() *thompson.State {
+	s := &thompson.State{ID: c.next, Trans: make(map[string][]*thompson.State)}
+	c.next++
+	return s
+}
+
+// of returns the copy of s, creating it on first use.
+func (c *copier) of(s *thompson.State) *thompson.State {
+	if cp, ok := c.copies[s]; ok {
+		return cp
+	}
+	cp := c.newState()
+	c.copies[s] = cp
+	return cp
+}
+
+// sortedStates returns the states of n ordered by ID.
+func sortedStates(n *thompson.NFA) []*thompson.State {
+	states := append([]*thompson.State(nil), n.States...)
+	sort.Slice(states, func(i, j int) bool { return states[i].ID < states[j].ID })
+	return states
+}
+
+// copyNFA clones every state and transition of n into c.
+func copyNFA(c *copier, n *thompson.NFA) {
+	for _, s := range sortedStates(n) {
+		cp := c.of(s)
+		for sym, outs := range s.Trans {
+			for _, t := range outs {
+				cp.Trans[sym] = append(cp.Trans[sym], c.of(t))
+			}
+		}
+	}
+}
+
+// finish assembles an NFA from a start state and accept states, collecting
+// every state reachable from start.
+func finish(start *thompson.State, accepts []*thompson.State) *thompson.NFA {
+	seen := map[*thompson.State]bool{start: true}
+	stack := []*thompson.State{start}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, outs := range s.Trans {
+			for _, t := range outs {
+				if !seen[t] {
+					seen[t] = true
+					stack = append(stack, t)
+				}
+			}
+		}
+	}
+
+	out := &thompson.NFA{Start: start}
+	for s := range seen {
+		out.States = append(out.States, s)
+	}
+	sort.Slice(out.States, func(i, j int) bool { return out.States[i].ID < out.States[j].ID })
+	for _, a := range accepts {
+		if seen[a] {
+			out.Accepts = append(out.Accepts, a)
+		}
+	}
+	sort.Slice(out.Accepts, func(i, j int) bool { return out.Accepts[i].ID < out.Accepts[j].ID })
+	if len(out.Accepts) == 1 {
+		out.Accept = out.Accepts[0]
+	}
+	return out
+}
+
+// coReachable returns the states of n from which some accept state can be
+// reached, following any transitions including ε.
+func coReachable(n *thompson.NFA) map[*thompson.State]bool {
+	rev := make(map[*thompson.State][]*thompson.State)
+	for _, s := range n.States {
+		for _, outs := range s.Trans {
+			for _, t := range outs {
+				rev[t] = append(rev[t], s)
+			}
+		}
+	}
+	live := make(map[*thompson.State]bool)
+	stack := append([]*thompson.State(nil), n.Accepts...)
+	for _, a := range stack {
+		live[a] = true
+	}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, p := range rev[s] {
+			if !live[p] {
+				live[p] = true
+				stack = append(stack, p)
+			}
+		}
+	}
+	return live
+}
+
+// Reverse returns an NFA for the reversal of L(n): every transition is
+// flipped, a new start state has ε-moves to the old accept states, and the
+// old start state becomes the only accept state.
+func Reverse(n *thompson.NFA) *thompson.NFA {
+	c := newCopier()
+	for _, s := range sortedStates(n) {
+		c.of(s)
+	}
+	for _, s := range sortedStates(n) {
+		for sym, outs := range s.Trans {
+			for _, t := range outs {
+				from := c.of(t)
+				from.Trans[sym] = append(from.Trans[sym], c.of(s))
+			}
+		}
+	}
+
+	start := c.newState()
+	for _, a := range n.Accepts {
+		start.Trans[thompson.Epsilon] = append(start.Trans[thompson.Epsilon], c.of(a))
+	}
+	return finish(start, []*thompson.State{c.of(n.Start)})
+}
+
+// Prefix returns an NFA for the prefix closure of L(n): every state from
+// which an accept state is still reachable becomes accepting.
+func Prefix(n *thompson.NFA) *thompson.NFA {
+	c := newCopier()
+	copyNFA(c, n)
+
+	live := coReachable(n)
+	var accepts []*thompson.State
+	for _, s := range sortedStates(n) {
+		if live[s] {
+			accepts = append(accepts, c.of(s))
+		}
+	}
+	return finish(c.of(n.Start), accepts)
+}
+
+// Suffix returns an NFA for the suffix closure of L(n): a new start state has
+// an ε-move to every state that lies on some accepting path.
+func Suffix(n *thompson.NFA) *thompson.NFA {
+	c := newCopier()
+	copyNFA(c, n)
+
+	live := coReachable(n)
+	start := c.newState()
+	var accepts []*thompson.State
+	for _, s := range sortedStates(n) {
+		if live[s] {
+			start.Trans[thompson.Epsilon] = append(start.Trans[thompson.Epsilon], c.of(s))
+		}
+		if n.IsAccept(s) {
+			accepts = append(accepts, c.of(s))
+		}
+	}
+	return finish(start, accepts)
+}
+
+// pair is a state of the product of two NFAs.
+type pair struct{ a, b *thompson.State }
+
+// productReach returns every pair reachable from the given pairs in the
+// product of n1 and n2: ε-moves advance one side, symbols advance both. Two
+// transitions are paired when their labels share a symbol, so a class such as
+// \d pairs with "7" or with \w.
+func productReach(from []pair) map[pair]bool {
+	seen := make(map[pair]bool)
+	stack := make([]pair, 0, len(from))
+	for _, p := range from {
+		if !seen[p] {
+			seen[p] = true
+			stack = append(stack, p)
+		}
+	}
+	push := func(p pair) {
+		if !seen[p] {
+			seen[p] = true
+			stack = append(stack, p)
+		}
+	}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, t := range p.a.Trans[thompson.Epsilon] {
+			push(pair{t, p.b})
+		}
+		for _, t := range p.b.Trans[thompson.Epsilon] {
+			push(pair{p.a, t})
+		}
+		for sa, outsA := range p.a.Trans {
+			if sa == thompson.Epsilon {
+				continue
+			}
+			for sb, outsB := range p.b.Trans {
+				if sb == thompson.Epsilon || !charclass.Overlap(sa, sb) {
+					continue
+				}
+				for _, ta := range outsA {
+					for _, tb := range outsB {
+						push(pair{ta, tb})
+					}
+				}
+			}
+		}
+	}
+	return seen
+}
+
+// RightQuotient returns an NFA for L(n1)/L(n2) = { x | xy ∈ L(n1) for some
+// y ∈ L(n2) }: a state of n1 accepts if, starting there together with the
+// start of n2, both automata can reach an accept state on the same y.
+func RightQuotient(n1, n2 *thompson.NFA) *thompson.NFA {
+	c := newCopier()
+	copyNFA(c, n1)
+
+	var accepts []*thompson.State
+	for _, s := range sortedStates(n1) {
+		for p := range productReach([]pair{{s, n2.Start}}) {
+			if n1.IsAccept(p.a) && n2.IsAccept(p.b) {
+				accepts = append(accepts, c.of(s))
+				break
+			}
+		}
+	}
+	return finish(c.of(n1.Start), accepts)
+}
+
+// LeftQuotient returns an NFA for L(n2)\L(n1) = { y | xy ∈ L(n1) for some
+// x ∈ L(n2) }: a new start state has ε-moves to every state of n1 that can be
+// reached on a string x that n2 accepts.
+func LeftQuotient(n1, n2 *thompson.NFA) *thompson.NFA {
+	c := newCopier()
+	copyNFA(c, n1)
+
+	reached := productReach([]pair{{n1.Start, n2.Start}})
+	entry := make(map[*thompson.State]bool)
+	for p := range reached {
+		if n2.IsAccept(p.b) {
+			entry[p.a] = true
+		}
+	}
+
+	start := c.newState()
+	var accepts []*thompson.State
+	for _, s := range sortedStates(n1) {
+		if entry[s] {
+			start.Trans[thompson.Epsilon] = append(start.Trans[thompson.Epsilon], c.of(s))
+		}
+		if n1.IsAccept(s) {
+			accepts = append(accepts, c.of(s))
+		}
+	}
+	return finish(start, accepts)
+}
+
+// Names are the operations that can follow "regex;w;" in an input line.
+var Names = []string{"reverse", "prefix", "suffix", "rquot", "lquot", "hom"}
+
+// IsOperation reports whether spec is one of Names, alone or followed by
+// ':' and its argument, as in "reverse" or "rquot:ab*".
+func IsOperation(spec string) bool {
+	name, _, _ := strings.Cut(spec, ":")
+	name = strings.TrimSpace(name)
+	for _, n := range Names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitLine splits an input line "regex;w" or "regex;w;operation". The part
+// after a second ';' is the operation only when IsOperation accepts it;
+// otherwise it stays in w, so "a;b;c" is w = "b;c" as it was before lines
+// could name an operation. ok is false when the line has no ';'.
+func SplitLine(raw string) (r, w, op string, ok bool) {
+	r, rest, ok := strings.Cut(raw, ";")
+	if !ok {
+		return "", "", "", false
+	}
+	w = rest
+	if before, after, found := strings.Cut(rest, ";"); found && IsOperation(after) {
+		w, op = before, strings.TrimSpace(after)
+	}
+	return strings.TrimSpace(r), strings.TrimSpace(w), op, true
+}
+
+// Homomorphism maps each symbol to the string of symbols that replaces it.
+// An empty image erases the symbol.
+type Homomorphism map[string][]string
+
+// ParseHomomorphism parses a homomorphism written as "a->xy, b->ε".
+// Symbols and images may use token symbols such as "<id>"; an image of "ε",
+// "epsilon" or nothing at all erases the symbol. A bare "e" is the letter e.
+func ParseHomomorphism(spec string) (Homomorphism, error) {
+	h := make(Homomorphism)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "->", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid mapping %q, expected 'a->xy'", item)
+		}
+		from, err := parseSymbols(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return nil, err
+		}
+		if len(from) != 1 {
+			return nil, fmt.Errorf("mapping %q must start with exactly one symbol", item)
+		}
+		to, err := parseSymbols(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := h[from[0]]; dup {
+			return nil, fmt.Errorf("symbol %q mapped twice", from[0])
+		}
+		h[from[0]] = to
+	}
+	if len(h) == 0 {
+		return nil, fmt.Errorf("empty homomorphism")
+	}
+	return h, nil
+}
+
+// parseSymbols splits s into symbols: token symbols such as "<id>" or single
+// runes. "ε" and "epsilon" on their own denote the empty string.
+func parseSymbols(s string) ([]string, error) {
+	if s == "" || s == "ε" || s == "epsilon" {
+		return nil, nil
+	}
+	chars := []rune(s)
+	var out []string
+	for i := 0; i < len(chars); i++ {
+		if chars[i] == '<' {
+			name, next, ok := config.ReadToken(chars, i)
+			if !ok {
+				return nil, fmt.Errorf("malformed token symbol in %q", s)
+			}
+			out = append(out, name)
+			i = next - 1
+			continue
+		}
+		if chars[i] == ' ' {
+			continue
+		}
+		if !config.IsAlphanumeric(chars[i]) {
+			return nil, fmt.Errorf("unexpected %q in %q", chars[i], s)
+		}
+		out = append(out, string(chars[i]))
+	}
+	return out, nil
+}
+
+// Apply returns an NFA for h(L(n)): every transition on a symbol a is
+// replaced by a chain of transitions spelling h(a), or an ε-move if h(a) is
+// empty. Symbols without a mapping are left unchanged.
+func (h Homomorphism) Apply(n *thompson.NFA) *thompson.NFA {
+	c := newCopier()
+	states := sortedStates(n)
+	for _, s := range states {
+		c.of(s)
+	}
+	for _, s := range states {
+		from := c.of(s)
+		syms := make([]string, 0, len(s.Trans))
+		for sym := range s.Trans {
+			syms = append(syms, sym)
+		}
+		sort.Strings(syms)
+
+		for _, sym := range syms {
+			image, mapped := h[sym]
+			if sym == thompson.Epsilon || !mapped {
+				image = []string{sym}
+			}
+			for _, t := range s.Trans[sym] {
+				to := c.of(t)
+				if len(image) == 0 {
+					from.Trans[thompson.Epsilon] = append(from.Trans[thompson.Epsilon], to)
+					continue
+				}
+				cur := from
+				for i, x := range image {
+					nxt := to
+					if i < len(image)-1 {
+						nxt = c.newState()
+					}
+					cur.Trans[x] = append(cur.Trans[x], nxt)
+					cur = nxt
+				}
+			}
+		}
+	}
+
+	accepts := make([]*thompson.State, 0, len(n.Accepts))
+	for _, a := range n.Accepts {
+		accepts = append(accepts, c.of(a))
+	}
+	return finish(c.of(n.Start), accepts)
+}
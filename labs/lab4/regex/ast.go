@@ -1,6 +1,8 @@
 // Package regex implements a simple regular expression parser that builds an
 // abstract syntax tree (AST) from a postfix expression.
 // It supports literals, concatenation, union, and Kleene star operations.
+// Literals are single runes, escaped runes such as "\\*", character classes
+// such as "\\d" or "\\p{Greek}", or bracketed token symbols such as "<id>".
 package regex
 
 import (
@@ -18,9 +20,12 @@ const (
 )
 
 // Node represents a node in the regex AST.
+// For literals, Val holds the symbol: a single rune such as "a", a class
+// label such as `\d` or `\p{Greek}`, or the name of a token symbol such as
+// "id" for "<id>".
 type Node struct {
 	Kind        Kind
-	Val         rune
+	Val         string
 	Left, Right *Node
 }
 
@@ -47,10 +52,26 @@ func BuildAST(postfix string) (*Node, error) {
 		return l, r, nil
 	}
 	// Process each rune in the postfix expression.
-	for _, c := range postfix {
+	chars := []rune(postfix)
+	for i := 0; i < len(chars); i++ {
+		c := chars[i]
 		switch {
+		case c == '<':
+			name, next, ok := config.ReadToken(chars, i)
+			if !ok {
+				return nil, fmt.Errorf("malformed token symbol at %q: names are two or more letters, digits, '_' or ':'", string(chars[i:]))
+			}
+			stack = append(stack, &Node{Kind: Literal, Val: name})
+			i = next - 1
+		case c == '\\':
+			sym, next, ok := config.ReadEscape(chars, i)
+			if !ok {
+				return nil, fmt.Errorf("malformed escape or unknown class at %q", string(chars[i:]))
+			}
+			stack = append(stack, &Node{Kind: Literal, Val: sym})
+			i = next - 1
 		case config.IsAlphanumeric(c):
-			stack = append(stack, &Node{Kind: Literal, Val: c})
+			stack = append(stack, &Node{Kind: Literal, Val: string(c)})
 		case c == '*':
 			x, err := pop1()
 			if err != nil {
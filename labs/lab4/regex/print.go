@@ -0,0 +1,131 @@
+package regex
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"lab4/charclass"
+	"lab4/config"
+)
+
+// prec returns the binding strength of a node in infix form:
+// union < concatenation < star < literal.
+func (n *Node) prec() int {
+	switch n.Kind {
+	case Union:
+		return 1
+	case Concat:
+		return 2
+	case Star:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// literalText renders a literal as it is written in a regex: classes as is,
+// token symbols as "<name>", and runes that would be read as operators
+// escaped, e.g. "\*".
+func literalText(val string) string {
+	r, size := utf8.DecodeRuneInString(val)
+	switch {
+	case charclass.IsClass(val):
+		return val
+	case size < len(val):
+		return "<" + val + ">"
+	case config.IsAlphanumeric(r):
+		return val
+	default:
+		return `\` + val
+	}
+}
+
+// Infix renders the AST as an infix regex with only the parentheses that
+// precedence requires. Concatenation and union are left-associative, so a
+// right operand of the same precedence is parenthesized: parsing the result
+// with the config and BuildAST pipeline yields the same tree.
+func (n *Node) Infix() string {
+	var b strings.Builder
+	n.writeInfix(&b)
+	return b.String()
+}
+
+func (n *Node) writeInfix(b *strings.Builder) {
+	operand := func(c *Node, min int) {
+		if c.prec() < min {
+			b.WriteByte('(')
+			c.writeInfix(b)
+			b.WriteByte(')')
+			return
+		}
+		c.writeInfix(b)
+	}
+	switch n.Kind {
+	case Literal:
+		b.WriteString(literalText(n.Val))
+	case Concat:
+		operand(n.Left, 2)
+		operand(n.Right, 3)
+	case Union:
+		operand(n.Left, 1)
+		b.WriteByte('|')
+		operand(n.Right, 2)
+	case Star:
+		operand(n.Left, 3)
+		b.WriteByte('*')
+	}
+}
+
+// Postfix renders the AST in the postfix notation read by BuildAST, with '.'
+// for concatenation.
+func (n *Node) Postfix() string {
+	var b strings.Builder
+	n.writePostfix(&b)
+	return b.String()
+}
+
+func (n *Node) writePostfix(b *strings.Builder) {
+	switch n.Kind {
+	case Literal:
+		b.WriteString(literalText(n.Val))
+	case Concat:
+		n.Left.writePostfix(b)
+		n.Right.writePostfix(b)
+		b.WriteByte('.')
+	case Union:
+		n.Left.writePostfix(b)
+		n.Right.writePostfix(b)
+		b.WriteByte('|')
+	case Star:
+		n.Left.writePostfix(b)
+		b.WriteByte('*')
+	}
+}
+
+// SExpr renders the AST as an S-expression, e.g. (concat a (star (union b c)))
+// for a(b|c)*.
+func (n *Node) SExpr() string {
+	var b strings.Builder
+	n.writeSExpr(&b)
+	return b.String()
+}
+
+func (n *Node) writeSExpr(b *strings.Builder) {
+	switch n.Kind {
+	case Literal:
+		b.WriteString(literalText(n.Val))
+		return
+	case Concat:
+		b.WriteString("(concat ")
+	case Union:
+		b.WriteString("(union ")
+	case Star:
+		b.WriteString("(star ")
+	}
+	n.Left.writeSExpr(b)
+	if n.Right != nil {
+		b.WriteByte(' ')
+		n.Right.writeSExpr(b)
+	}
+	b.WriteByte(')')
+}
@@ -0,0 +1,298 @@
+// Package repl implements lab4's interactive mode: set a regex once with
+// ":regex", then type strings to simulate them against its NFA, inspect the
+// postfix form and AST, draw the NFA, or print statistics. On a terminal the
+// line editor keeps a history (up/down arrows) and completes commands on Tab.
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/term"
+
+	"lab4/config"
+	"lab4/graphviz"
+	"lab4/nfa"
+	"lab4/regex"
+	"lab4/thompson"
+)
+
+// Prompt is shown before each line read from a terminal.
+const Prompt = "lab4> "
+
+// commands lists the REPL commands with their help text.
+var commands = map[string]string{
+	":regex":   ":regex <r>      set the current regex, e.g. :regex a(b|c)*",
+	":postfix": ":postfix        show the expanded, formatted and postfix forms",
+	":ast":     ":ast            show the AST, its minimal infix form and S-expression",
+	":steps":   ":steps          show the Shunting Yard steps as a table",
+	":dot":     ":dot <file>     write the current NFA as a DOT file",
+	":stats":   ":stats          show state and transition counts",
+	":tokens":  ":tokens on|off  treat input lines as whitespace-separated tokens",
+	":help":    ":help           show this help",
+	":quit":    ":quit           leave the REPL",
+}
+
+// Session holds the current regex and every intermediate form built from it.
+type Session struct {
+	Regex     string
+	Expanded  string
+	Formatted string
+	Postfix   string
+	AST       *regex.Node
+	NFA       *thompson.NFA
+	Tokens    bool
+}
+
+// SetRegex compiles r and makes it the current regex. On error the previous
+// regex is kept.
+func (s *Session) SetRegex(r string) error {
+	expanded := config.ExpandRegexExtensions(r)
+	formatted := config.FormatRegex(expanded)
+	postfix := config.InfixToPostfixTo(io.Discard, formatted)
+	ast, err := regex.BuildAST(postfix)
+	if err != nil {
+		return err
+	}
+	n, err := thompson.Build(ast)
+	if err != nil {
+		return err
+	}
+	*s = Session{
+		Regex: r, Expanded: expanded, Formatted: formatted, Postfix: postfix,
+		AST: ast, NFA: n, Tokens: s.Tokens,
+	}
+	return nil
+}
+
+// Exec runs one REPL line and writes its result to out. It returns false
+// when the session should end.
+func (s *Session) Exec(line string, out io.Writer) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return true
+	}
+	if !strings.HasPrefix(line, ":") {
+		s.simulate(line, out)
+		return true
+	}
+
+	cmd, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	switch cmd {
+	case ":quit", ":q":
+		return false
+	case ":help":
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintln(out, "  "+commands[name])
+		}
+		fmt.Fprintln(out, "  any other line is simulated against the current regex")
+	case ":regex":
+		if arg == "" {
+			if s.NFA == nil {
+				fmt.Fprintln(out, "no regex set")
+			} else {
+				fmt.Fprintf(out, "current regex: %s\n", s.Regex)
+			}
+			return true
+		}
+		if err := s.SetRegex(arg); err != nil {
+			fmt.Fprintf(out, "error: %v\n", err)
+			return true
+		}
+		fmt.Fprintf(out, "regex set: %s (%d states)\n", s.Regex, len(s.NFA.States))
+	case ":postfix":
+		if s.needRegex(out) {
+			fmt.Fprintf(out, "  expanded  : %s\n", s.Expanded)
+			fmt.Fprintf(out, "  formatted : %s\n", s.Formatted)
+			fmt.Fprintf(out, "  postfix   : %s\n", s.Postfix)
+		}
+	case ":ast":
+		if s.needRegex(out) {
+			fmt.Fprintf(out, "  infix : %s\n", s.AST.Infix())
+			fmt.Fprintf(out, "  sexpr : %s\n", s.AST.SExpr())
+			writeAST(out, s.AST, "  ")
+		}
+	case ":steps":
+		if s.needRegex(out) {
+			_, steps := config.InfixToPostfixSteps(s.Formatted)
+			config.WriteStepTable(out, steps)
+		}
+	case ":dot":
+		if !s.needRegex(out) {
+			return true
+		}
+		if arg == "" {
+			fmt.Fprintln(out, "usage: :dot <file>")
+			return true
+		}
+		if err := graphviz.WriteDOT(s.NFA, arg); err != nil {
+			fmt.Fprintf(out, "error: %v\n", err)
+			return true
+		}
+		fmt.Fprintf(out, "DOT saved: %s\n", arg)
+	case ":stats":
+		if s.needRegex(out) {
+			s.writeStats(out)
+		}
+	case ":tokens":
+		switch arg {
+		case "on":
+			s.Tokens = true
+		case "off":
+			s.Tokens = false
+		default:
+			fmt.Fprintln(out, "usage: :tokens on|off")
+			return true
+		}
+		fmt.Fprintf(out, "token mode: %s\n", arg)
+	default:
+		fmt.Fprintf(out, "unknown command %s (try :help)\n", cmd)
+	}
+	return true
+}
+
+// needRegex reports whether a regex is set, telling the user otherwise.
+func (s *Session) needRegex(out io.Writer) bool {
+	if s.NFA == nil {
+		fmt.Fprintln(out, "no regex set, use :regex <r> first")
+		return false
+	}
+	return true
+}
+
+// simulate runs w through the current NFA.
+func (s *Session) simulate(w string, out io.Writer) {
+	if !s.needRegex(out) {
+		return
+	}
+	var accepted bool
+	if s.Tokens {
+		accepted = nfa.SimulateTokens(s.NFA, strings.Fields(w))
+	} else {
+		accepted = nfa.Simulate(s.NFA, w)
+	}
+	ans := map[bool]string{true: "sí", false: "no"}[accepted]
+	fmt.Fprintf(out, "w ∈ L(%s)? %s   (w = %q)\n", s.Regex, ans, w)
+}
+
+// writeStats prints state, accept-state and transition counts of the NFA.
+func (s *Session) writeStats(out io.Writer) {
+	eps, sym := 0, 0
+	alphabet := make(map[string]bool)
+	for _, st := range s.NFA.States {
+		for label, outs := range st.Trans {
+			if label == thompson.Epsilon {
+				eps += len(outs)
+				continue
+			}
+			sym += len(outs)
+			alphabet[label] = true
+		}
+	}
+	fmt.Fprintf(out, "  states      : %d\n", len(s.NFA.States))
+	fmt.Fprintf(out, "  accepting   : %d\n", len(s.NFA.Accepts))
+	fmt.Fprintf(out, "  transitions : %d (%d on symbols, %d ε)\n", eps+sym, sym, eps)
+	fmt.Fprintf(out, "  alphabet    : %d symbols\n", len(alphabet))
+}
+
+// writeAST prints the AST as an indented tree, one node per line.
+func writeAST(out io.Writer, n *regex.Node, indent string) {
+	if n == nil {
+		return
+	}
+	switch n.Kind {
+	case regex.Literal:
+		fmt.Fprintf(out, "%sLiteral %s\n", indent, n.Val)
+	case regex.Concat:
+		fmt.Fprintf(out, "%sConcat\n", indent)
+	case regex.Union:
+		fmt.Fprintf(out, "%sUnion\n", indent)
+	case regex.Star:
+		fmt.Fprintf(out, "%sStar\n", indent)
+	}
+	writeAST(out, n.Left, indent+"  ")
+	writeAST(out, n.Right, indent+"  ")
+}
+
+// Complete is the Tab handler (term.Terminal.AutoCompleteCallback): it
+// extends a partial command to the longest prefix shared by every matching
+// command.
+func Complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' || !strings.HasPrefix(line, ":") || strings.Contains(line[:pos], " ") {
+		return "", 0, false
+	}
+	prefix := line[:pos]
+	var matches []string
+	for name := range commands {
+		if strings.HasPrefix(name, prefix) {
+			matches = append(matches, name)
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+	sort.Strings(matches)
+	common := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(m, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	if len(matches) == 1 {
+		common += " "
+	}
+	return common + line[pos:], len(common), true
+}
+
+// Run reads lines from in and executes them in s until EOF or :quit. When in
+// is a terminal it is put in raw mode for line editing, history and Tab
+// completion; otherwise lines are read plainly, which keeps the REPL
+// scriptable.
+func Run(in *os.File, out io.Writer, s *Session) error {
+	fmt.Fprintln(out, "lab4 REPL — :help for commands, :quit to leave")
+
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		sc := bufio.NewScanner(in)
+		for sc.Scan() {
+			if !s.Exec(sc.Text(), out) {
+				return nil
+			}
+		}
+		return sc.Err()
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{in, out}, Prompt)
+	t.AutoCompleteCallback = Complete
+	for {
+		line, err := t.ReadLine()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !s.Exec(line, t) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,343 @@
+// Package server exposes the lab4 regex → NFA pipeline as a small JSON HTTP
+// service:
+//
+//	POST /compile  {"regex": "a(b|c)*", "format": "json"|"dot"}
+//	POST /match    {"regex": "a(b|c)*", "strings": ["abc", "ad"]}
+//
+// Request bodies, regex length, '+' expansions and NFA size are bounded by
+// Limits so a single request cannot exhaust the server.
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"lab4/config"
+	"lab4/graphviz"
+	"lab4/nfa"
+	"lab4/regex"
+	"lab4/thompson"
+)
+
+// Limits bounds the work a single request may cause.
+type Limits struct {
+	MaxBodyBytes int64 // size of the request body
+	MaxRegexLen  int   // runes in the regex as written
+	MaxRepeats   int   // '+' operators, each of which duplicates its operand
+	MaxStates    int   // states in the resulting NFA
+	MaxStrings   int   // strings per /match request
+	MaxStringLen int   // runes per matched string
+}
+
+// DefaultLimits are suitable for a classroom web app.
+var DefaultLimits = Limits{
+	MaxBodyBytes: 64 << 10,
+	MaxRegexLen:  256,
+	MaxRepeats:   8,
+	MaxStates:    5000,
+	MaxStrings:   100,
+	MaxStringLen: 1000,
+}
+
+// CompileRequest is the body of POST /compile.
+type CompileRequest struct {
+	Regex  string `json:"regex"`
+	Format string `json:"format,omitempty"` // "json" (default) or "dot"
+}
+
+// CompileResponse is the reply to POST /compile. Exactly one of NFA and DOT
+// is set, depending on the requested format.
+type CompileResponse struct {
+	Regex     string   `json:"regex"`
+	Expanded  string   `json:"expanded"`
+	Formatted string   `json:"formatted"`
+	Postfix   string   `json:"postfix"`
+	AST       *ASTNode `json:"ast"`
+	NFA       *NFAJSON `json:"nfa,omitempty"`
+	DOT       string   `json:"dot,omitempty"`
+}
+
+// MatchRequest is the body of POST /match. With Tokens set, each string is a
+// whitespace-separated sequence of token symbols.
+type MatchRequest struct {
+	Regex   string   `json:"regex"`
+	Strings []string `json:"strings"`
+	Tokens  bool     `json:"tokens,omitempty"`
+}
+
+// MatchResult is the verdict for one string.
+type MatchResult struct {
+	Input    string `json:"input"`
+	Accepted bool   `json:"accepted"`
+}
+
+// MatchResponse is the reply to POST /match, with results in request order.
+type MatchResponse struct {
+	Regex   string        `json:"regex"`
+	Results []MatchResult `json:"results"`
+}
+
+// ASTNode is the JSON form of a regex.Node.
+type ASTNode struct {
+	Kind  string   `json:"kind"` // literal, concat, union or star
+	Value string   `json:"value,omitempty"`
+	Left  *ASTNode `json:"left,omitempty"`
+	Right *ASTNode `json:"right,omitempty"`
+}
+
+// Transition is one edge of an NFA; Symbol is "ε" for ε-transitions.
+type Transition struct {
+	From   int    `json:"from"`
+	Symbol string `json:"symbol"`
+	To     int    `json:"to"`
+}
+
+// NFAJSON is the JSON form of a thompson.NFA, with states and transitions
+// sorted for stable output.
+type NFAJSON struct {
+	Start       int          `json:"start"`
+	Accepts     []int        `json:"accepts"`
+	States      []int        `json:"states"`
+	Transitions []Transition `json:"transitions"`
+}
+
+// errorResponse is the body of every non-2xx reply.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// httpError carries the status code to report for a failed request.
+type httpError struct {
+	status int
+	msg    string
+}
+
+func (e *httpError) Error() string { return e.msg }
+
+// badRequest returns a 400 error with a formatted message.
+func badRequest(format string, args ...any) error {
+	return &httpError{status: http.StatusBadRequest, msg: fmt.Sprintf(format, args...)}
+}
+
+// NewHandler returns the service's HTTP handler enforcing the given limits.
+func NewHandler(l Limits) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/compile", func(w http.ResponseWriter, r *http.Request) {
+		var req CompileRequest
+		if err := decode(w, r, l, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+		resp, err := compile(req, l)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+	mux.HandleFunc("/match", func(w http.ResponseWriter, r *http.Request) {
+		var req MatchRequest
+		if err := decode(w, r, l, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+		resp, err := match(req, l)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+	return mux
+}
+
+// decode checks the method and reads a size-limited JSON body into v.
+func decode(w http.ResponseWriter, r *http.Request, l Limits, v any) error {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		return &httpError{status: http.StatusMethodNotAllowed, msg: "use POST"}
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, l.MaxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			return &httpError{status: http.StatusRequestEntityTooLarge,
+				msg: fmt.Sprintf("request body exceeds %d bytes", l.MaxBodyBytes)}
+		}
+		return badRequest("invalid JSON: %v", err)
+	}
+	if dec.More() {
+		return badRequest("invalid JSON: trailing data after the request object")
+	}
+	return nil
+}
+
+// writeJSON writes v as the JSON response body with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError reports err as a JSON error, using its status if it has one.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var he *httpError
+	if errors.As(err, &he) {
+		status = he.status
+	}
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// compiled holds every stage of the pipeline for one regex.
+type compiled struct {
+	expanded, formatted, postfix string
+	ast                          *regex.Node
+	nfa                          *thompson.NFA
+}
+
+// build runs the pipeline on r after checking it against the limits.
+func build(r string, l Limits) (*compiled, error) {
+	r = strings.TrimSpace(r)
+	if r == "" {
+		return nil, badRequest("empty regex")
+	}
+	if n := len([]rune(r)); n > l.MaxRegexLen {
+		return nil, badRequest("regex has %d characters, limit is %d", n, l.MaxRegexLen)
+	}
+	// each '+' copies its operand, so nested ones grow the regex exponentially
+	if n := strings.Count(r, "+"); n > l.MaxRepeats {
+		return nil, badRequest("regex uses '+' %d times, limit is %d", n, l.MaxRepeats)
+	}
+
+	c := &compiled{}
+	c.expanded = config.ExpandRegexExtensions(r)
+	c.formatted = config.FormatRegex(c.expanded)
+	c.postfix = config.InfixToPostfixTo(io.Discard, c.formatted)
+	ast, err := regex.BuildAST(c.postfix)
+	if err != nil {
+		return nil, badRequest("invalid regex: %v", err)
+	}
+	c.ast = ast
+	if c.nfa, err = thompson.Build(ast); err != nil {
+		return nil, badRequest("invalid regex: %v", err)
+	}
+	if n := len(c.nfa.States); n > l.MaxStates {
+		return nil, badRequest("NFA has %d states, limit is %d", n, l.MaxStates)
+	}
+	return c, nil
+}
+
+// compile handles a /compile request.
+func compile(req CompileRequest, l Limits) (*CompileResponse, error) {
+	c, err := build(req.Regex, l)
+	if err != nil {
+		return nil, err
+	}
+	resp := &CompileResponse{
+		Regex:     strings.TrimSpace(req.Regex),
+		Expanded:  c.expanded,
+		Formatted: c.formatted,
+		Postfix:   c.postfix,
+		AST:       astJSON(c.ast),
+	}
+	switch req.Format {
+	case "", "json":
+		resp.NFA = nfaJSON(c.nfa)
+	case "dot":
+		var b bytes.Buffer
+		if err := graphviz.EncodeDOT(&b, c.nfa); err != nil {
+			return nil, err
+		}
+		resp.DOT = b.String()
+	default:
+		return nil, badRequest("unknown format %q, use json or dot", req.Format)
+	}
+	return resp, nil
+}
+
+// match handles a /match request.
+func match(req MatchRequest, l Limits) (*MatchResponse, error) {
+	if len(req.Strings) == 0 {
+		return nil, badRequest("no strings to match")
+	}
+	if len(req.Strings) > l.MaxStrings {
+		return nil, badRequest("%d strings, limit is %d", len(req.Strings), l.MaxStrings)
+	}
+	for i, s := range req.Strings {
+		if n := len([]rune(s)); n > l.MaxStringLen {
+			return nil, badRequest("string %d has %d characters, limit is %d", i, n, l.MaxStringLen)
+		}
+	}
+	c, err := build(req.Regex, l)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &MatchResponse{Regex: strings.TrimSpace(req.Regex), Results: make([]MatchResult, len(req.Strings))}
+	for i, s := range req.Strings {
+		var ok bool
+		if req.Tokens {
+			ok = nfa.SimulateTokens(c.nfa, strings.Fields(s))
+		} else {
+			ok = nfa.Simulate(c.nfa, s)
+		}
+		resp.Results[i] = MatchResult{Input: s, Accepted: ok}
+	}
+	return resp, nil
+}
+
+// astJSON converts an AST to its JSON form.
+func astJSON(n *regex.Node) *ASTNode {
+	if n == nil {
+		return nil
+	}
+	out := &ASTNode{Left: astJSON(n.Left), Right: astJSON(n.Right)}
+	switch n.Kind {
+	case regex.Literal:
+		out.Kind, out.Value = "literal", n.Val
+	case regex.Concat:
+		out.Kind = "concat"
+	case regex.Union:
+		out.Kind = "union"
+	case regex.Star:
+		out.Kind = "star"
+	}
+	return out
+}
+
+// nfaJSON converts an NFA to its JSON form.
+func nfaJSON(n *thompson.NFA) *NFAJSON {
+	out := &NFAJSON{Start: n.Start.ID, Accepts: []int{}, States: []int{}, Transitions: []Transition{}}
+	for _, a := range n.Accepts {
+		out.Accepts = append(out.Accepts, a.ID)
+	}
+	for _, s := range n.States {
+		out.States = append(out.States, s.ID)
+		for sym, targets := range s.Trans {
+			for _, t := range targets {
+				out.Transitions = append(out.Transitions, Transition{From: s.ID, Symbol: sym, To: t.ID})
+			}
+		}
+	}
+	sort.Ints(out.Accepts)
+	sort.Ints(out.States)
+	sort.Slice(out.Transitions, func(i, j int) bool {
+		a, b := out.Transitions[i], out.Transitions[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		return a.To < b.To
+	})
+	return out
+}
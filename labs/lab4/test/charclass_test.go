@@ -0,0 +1,78 @@
+package test
+
+import (
+	"io"
+	"testing"
+
+	"lab4/config"
+	"lab4/nfa"
+	"lab4/ops"
+	"lab4/regex"
+	"lab4/thompson"
+)
+
+// \d, \w and \s are Unicode-aware, unlike in Go's regexp, so the fuzz test
+// cannot cover them; these cases pin down their meaning.
+func TestClassesMatch(t *testing.T) {
+	cases := []struct {
+		regex, w string
+		want     bool
+	}{
+		{`\d+`, "2024", true},
+		{`\d+`, "٣٤", true}, // Arabic-Indic digits
+		{`\d+`, "20a4", false},
+		{`\D`, "x", true},
+		{`\D`, "7", false},
+		{`\w+`, "héllo_1", true},
+		{`\w`, "-", false},
+		{`\W`, "-", true},
+		{`a\s+b`, "a \t b", true},
+		{`a\s+b`, "ab", false},
+		{`\S*`, "ñ!", true},
+		{`\p{L}\P{L}`, "ж7", true},
+		{`\p{Greek}+`, "λόγος", true},
+		{`\p{Greek}+`, "logos", false},
+		{`a\*b`, "a*b", true},
+		{`a\*b`, "ab", false},
+	}
+	for _, c := range cases {
+		got, err := lab4Accepts(c.regex, c.w)
+		if err != nil {
+			t.Errorf("%s on %q: %v", c.regex, c.w, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%s on %q = %v, want %v", c.regex, c.w, got, c.want)
+		}
+	}
+}
+
+func TestClassesInQuotient(t *testing.T) {
+	// \d and \w share the digits, so L(a\d)/L(\w) = {a}
+	q := ops.RightQuotient(build(t, `a\d`), build(t, `\w`))
+	for w, want := range map[string]bool{"a": true, "": false, "a1": false} {
+		if got := nfa.Simulate(q, w); got != want {
+			t.Errorf("L(a\\d)/L(\\w) on %q = %v, want %v", w, got, want)
+		}
+	}
+	// \d and \s share nothing, so the quotient is empty
+	q = ops.RightQuotient(build(t, `a\d`), build(t, `\s`))
+	if nfa.Simulate(q, "a") {
+		t.Errorf("L(a\\d)/L(\\s) accepts %q", "a")
+	}
+}
+
+// build compiles r through the full lab4 pipeline.
+func build(t *testing.T, r string) *thompson.NFA {
+	t.Helper()
+	postfix := config.InfixToPostfixTo(io.Discard, config.FormatRegex(config.ExpandRegexExtensions(r)))
+	ast, err := regex.BuildAST(postfix)
+	if err != nil {
+		t.Fatalf("BuildAST(%q): %v", postfix, err)
+	}
+	n, err := thompson.Build(ast)
+	if err != nil {
+		t.Fatalf("thompson.Build(%q): %v", r, err)
+	}
+	return n
+}
@@ -0,0 +1,314 @@
+package test
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"lab4/config"
+	"lab4/nfa"
+	"lab4/ops"
+	"lab4/regex"
+	"lab4/thompson"
+)
+
+// genKind enumerates the shapes the random regex generator can produce.
+type genKind int
+
+const (
+	genLit genKind = iota
+	genEps
+	genCat
+	genAlt
+	genStar
+	genPlus
+	genOpt
+)
+
+// genNode is a random regex tree. It is rendered twice: once in lab4 syntax
+// and once in Go regexp syntax, so both engines see the same language.
+type genNode struct {
+	kind genKind
+	lit  string
+	l, r *genNode
+}
+
+// maxGenDepth bounds the size of generated regexes.
+const maxGenDepth = 4
+
+// genAlphabet is the set of literals used in regexes, and genClasses the
+// Unicode classes, whose syntax lab4 shares with Go's regexp. Input strings
+// also use 'c', the two-byte 'é' and the Greek 'α' so that symbols outside the
+// regex alphabet, every class and UTF-8 sequences split across writes are
+// exercised.
+var (
+	genAlphabet   = []string{"a", "b"}
+	genClasses    = []string{`\p{Greek}`, `\P{Latin}`, `\p{Ll}`}
+	inputAlphabet = []rune{'a', 'b', 'c', 'é', 'α'}
+)
+
+// byteSource hands out fuzz bytes one at a time, returning 0 once exhausted.
+type byteSource struct {
+	data []byte
+	pos  int
+}
+
+func (s *byteSource) next() byte {
+	if s.pos >= len(s.data) {
+		return 0
+	}
+	b := s.data[s.pos]
+	s.pos++
+	return b
+}
+
+// genRegex builds a random regex tree driven by the fuzz bytes.
+func genRegex(s *byteSource, depth int) *genNode {
+	b := s.next()
+	if depth >= maxGenDepth {
+		b %= 2
+	}
+	switch b % 7 {
+	case 0:
+		return &genNode{kind: genLit, lit: genAlphabet[int(s.next())%len(genAlphabet)]}
+	case 1:
+		// keep ε and classes rarer than plain literals
+		switch s.next() % 4 {
+		case 0:
+			return &genNode{kind: genEps}
+		case 1:
+			return &genNode{kind: genLit, lit: genClasses[int(s.next())%len(genClasses)]}
+		}
+		return &genNode{kind: genLit, lit: genAlphabet[int(s.next())%len(genAlphabet)]}
+	case 2:
+		return &genNode{kind: genCat, l: genRegex(s, depth+1), r: genRegex(s, depth+1)}
+	case 3:
+		return &genNode{kind: genAlt, l: genRegex(s, depth+1), r: genRegex(s, depth+1)}
+	case 4:
+		return &genNode{kind: genStar, l: genRegex(s, depth+1)}
+	case 5:
+		return &genNode{kind: genPlus, l: genRegex(s, depth+1)}
+	default:
+		return &genNode{kind: genOpt, l: genRegex(s, depth+1)}
+	}
+}
+
+// genInput maps arbitrary fuzz bytes onto the input alphabet.
+func genInput(raw []byte) string {
+	var b strings.Builder
+	for _, c := range raw {
+		b.WriteRune(inputAlphabet[int(c)%len(inputAlphabet)])
+	}
+	return b.String()
+}
+
+// prec returns the binding strength of a node when rendered as infix.
+func (n *genNode) prec() int {
+	switch n.kind {
+	case genAlt:
+		return 1
+	case genCat:
+		return 2
+	case genStar, genPlus, genOpt:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// lab4 renders the tree in the syntax accepted by ExpandRegexExtensions,
+// using only the parentheses that precedence requires.
+func (n *genNode) lab4() string {
+	wrap := func(c *genNode, min int) string {
+		if c.prec() < min {
+			return "(" + c.lab4() + ")"
+		}
+		return c.lab4()
+	}
+	switch n.kind {
+	case genLit:
+		return n.lit
+	case genEps:
+		return "ε"
+	case genCat:
+		return wrap(n.l, 2) + wrap(n.r, 3)
+	case genAlt:
+		return wrap(n.l, 1) + "|" + wrap(n.r, 2)
+	case genStar:
+		return wrap(n.l, 3) + "*"
+	case genPlus:
+		return wrap(n.l, 3) + "+"
+	default:
+		return wrap(n.l, 3) + "?"
+	}
+}
+
+// goSyntax renders the tree for Go's regexp package. Nested repetitions are
+// grouped because RE2 rejects operators such as "a*+".
+func (n *genNode) goSyntax() string {
+	wrap := func(c *genNode, min int) string {
+		if c.prec() < min || (min == 3 && c.prec() == 3) {
+			return "(?:" + c.goSyntax() + ")"
+		}
+		return c.goSyntax()
+	}
+	switch n.kind {
+	case genLit:
+		return n.lit
+	case genEps:
+		return "(?:)"
+	case genCat:
+		return wrap(n.l, 2) + wrap(n.r, 3)
+	case genAlt:
+		return wrap(n.l, 1) + "|" + wrap(n.r, 2)
+	case genStar:
+		return wrap(n.l, 3) + "*"
+	case genPlus:
+		return wrap(n.l, 3) + "+"
+	default:
+		return wrap(n.l, 3) + "?"
+	}
+}
+
+// shrinkCandidates returns smaller trees derived from n: each child in place
+// of its parent, a plain literal, and the same shrinks applied recursively.
+func (n *genNode) shrinkCandidates() []*genNode {
+	var out []*genNode
+	if n.l != nil {
+		out = append(out, n.l)
+	}
+	if n.r != nil {
+		out = append(out, n.r)
+	}
+	if n.kind != genLit {
+		out = append(out, &genNode{kind: genLit, lit: "a"})
+	}
+	if n.l != nil {
+		for _, c := range n.l.shrinkCandidates() {
+			cp := *n
+			cp.l = c
+			out = append(out, &cp)
+		}
+	}
+	if n.r != nil {
+		for _, c := range n.r.shrinkCandidates() {
+			cp := *n
+			cp.r = c
+			out = append(out, &cp)
+		}
+	}
+	return out
+}
+
+// lab4Accepts runs the full lab4 pipeline on r and w. It checks that the AST
+// prints back to the same postfix and round-trips through its infix form, and
+// that the ε-free NFA, the streaming Matcher and the reversed NFA (on
+// reversed w) give the same verdict.
+func lab4Accepts(r, w string) (bool, error) {
+	postfix := config.InfixToPostfixTo(io.Discard, config.FormatRegex(config.ExpandRegexExtensions(r)))
+
+	ast, err := regex.BuildAST(postfix)
+	if err != nil {
+		return false, fmt.Errorf("BuildAST(%q): %w", postfix, err)
+	}
+	n, err := thompson.Build(ast)
+	if err != nil {
+		return false, fmt.Errorf("thompson.Build: %w", err)
+	}
+	if got := ast.Postfix(); got != postfix {
+		return false, fmt.Errorf("AST prints as postfix %q, parsed from %q", got, postfix)
+	}
+	if again, err := regex.BuildAST(config.InfixToPostfixTo(io.Discard, config.FormatRegex(ast.Infix()))); err != nil || !reflect.DeepEqual(again, ast) {
+		return false, fmt.Errorf("AST printed as %q does not parse back to the same tree (err %v)", ast.Infix(), err)
+	}
+	accepted := nfa.Simulate(n, w)
+	if noEps := nfa.Simulate(thompson.EliminateEpsilon(n), w); noEps != accepted {
+		return false, fmt.Errorf("ε-free NFA says %v, Thompson NFA says %v", noEps, accepted)
+	}
+	m := nfa.NewMatcher(n)
+	for i := 0; i < len(w); i++ {
+		m.Write([]byte{w[i]}) // one byte at a time splits every multi-byte rune
+	}
+	if m.Accepting() != accepted {
+		return false, fmt.Errorf("streaming Matcher says %v, Simulate says %v", m.Accepting(), accepted)
+	}
+	if rev := nfa.Simulate(ops.Reverse(n), reverse(w)); rev != accepted {
+		return false, fmt.Errorf("reversed NFA on reversed w says %v, Thompson NFA says %v", rev, accepted)
+	}
+	return accepted, nil
+}
+
+// reverse returns w with its runes in reverse order.
+func reverse(w string) string {
+	r := []rune(w)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
+
+// disagrees reports whether lab4 and Go's regexp give different verdicts for
+// the regex tree n on input w, along with a description of both verdicts.
+func disagrees(n *genNode, w string) (bool, string) {
+	want := regexp.MustCompile("^(?:" + n.goSyntax() + ")$").MatchString(w)
+	got, err := lab4Accepts(n.lab4(), w)
+	if err != nil {
+		return true, fmt.Sprintf("lab4 error: %v (regexp says %v)", err, want)
+	}
+	if got != want {
+		return true, fmt.Sprintf("lab4 says %v, regexp says %v", got, want)
+	}
+	return false, ""
+}
+
+// minimize greedily shrinks the regex tree and the input while the two
+// engines keep disagreeing, so failures are reported in their smallest form.
+func minimize(n *genNode, w string) (*genNode, string, string) {
+	_, why := disagrees(n, w)
+	for changed := true; changed; {
+		changed = false
+		for _, c := range n.shrinkCandidates() {
+			if bad, msg := disagrees(c, w); bad {
+				n, why, changed = c, msg, true
+				break
+			}
+		}
+		for i := 0; i < len(w) && !changed; i++ {
+			shorter := w[:i] + w[i+1:]
+			if bad, msg := disagrees(n, shorter); bad {
+				w, why, changed = shorter, msg, true
+			}
+		}
+	}
+	return n, w, why
+}
+
+// FuzzSimulateAgainstRegexp compares the BuildAST → thompson.Build →
+// nfa.Simulate pipeline with Go's regexp on random regexes and strings.
+func FuzzSimulateAgainstRegexp(f *testing.F) {
+	f.Add([]byte{2, 0, 0, 4, 3, 0, 0, 0, 1}, []byte{0, 1, 1})
+	f.Add([]byte{5, 3, 0, 0, 0, 1}, []byte{0, 1, 0})
+	f.Add([]byte{6, 2, 0, 0, 0, 1}, []byte{})
+	f.Add([]byte{3, 1, 0, 0, 4, 0, 1}, []byte{1, 1})
+	f.Add([]byte{2, 5, 0, 0, 6, 0, 1}, []byte{0, 0, 1, 2})
+	// regressions: "a*?" and "b+?" used to expand into malformed infix
+	f.Add([]byte{6, 4, 0, 0}, []byte{})
+	f.Add([]byte{6, 5, 0, 1}, []byte{})
+	// classes: "\p{Greek}+" and "a\P{Latin}?"
+	f.Add([]byte{5, 1, 1, 0}, []byte{4, 4})
+	f.Add([]byte{2, 0, 0, 6, 1, 1, 1}, []byte{0, 2})
+
+	f.Fuzz(func(t *testing.T, re, in []byte) {
+		n := genRegex(&byteSource{data: re}, 0)
+		w := genInput(in)
+		if bad, _ := disagrees(n, w); !bad {
+			return
+		}
+		n, w, why := minimize(n, w)
+		t.Fatalf("mismatch on minimized case\n  lab4 regex : %s\n  Go regexp  : ^(?:%s)$\n  w          : %q\n  %s",
+			n.lab4(), n.goSyntax(), w, why)
+	})
+}
@@ -0,0 +1,88 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lab4/grammar"
+	"lab4/nfa"
+)
+
+// project2 is where cmd/cyk lives, relative to this directory.
+const project2 = "../../../projects/project2"
+
+// buildCYK compiles cmd/cyk into a temporary directory, skipping the test
+// when project2 or the go tool is not available.
+func buildCYK(t *testing.T) string {
+	t.Helper()
+	if _, err := os.Stat(filepath.Join(project2, "cmd", "cyk")); err != nil {
+		t.Skipf("cmd/cyk not found: %v", err)
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not in PATH")
+	}
+	bin := filepath.Join(t.TempDir(), "cyk")
+	cmd := exec.Command(goTool, "build", "-o", bin, "./cmd/cyk")
+	cmd.Dir = project2
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("building cmd/cyk: %v\n%s", err, out)
+	}
+	return bin
+}
+
+// words returns every string over alphabet of length 1..maxLen.
+func words(alphabet []string, maxLen int) [][]string {
+	var out [][]string
+	level := [][]string{nil}
+	for l := 1; l <= maxLen; l++ {
+		var next [][]string
+		for _, w := range level {
+			for _, a := range alphabet {
+				next = append(next, append(append([]string(nil), w...), a))
+			}
+		}
+		out = append(out, next...)
+		level = next
+	}
+	return out
+}
+
+// The grammar written by grammar.WriteFile must be read by cmd/cyk and
+// accept exactly the non-empty strings the NFA accepts (cyk cannot take the
+// empty string). (ab)* and a* cover languages that contain ε.
+func TestRightLinearGrammarAgreesWithCYK(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs cmd/cyk")
+	}
+	bin := buildCYK(t)
+	dir := t.TempDir()
+
+	for i, r := range []string{"a*b", "(a|b)*abb", "ab|ba", "(ab)*", "a*", "a(b|ε)a"} {
+		n := build(t, r)
+		g, err := grammar.FromNFA(n)
+		if err != nil {
+			t.Fatalf("%s: FromNFA: %v", r, err)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("grammar_%d.txt", i))
+		if err := grammar.WriteFile(g, path); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, w := range words([]string{"a", "b"}, 4) {
+			want := nfa.Simulate(n, strings.Join(w, ""))
+			out, err := exec.Command(bin, "--grammar", path, "--input", strings.Join(w, " ")).CombinedOutput()
+			if err != nil {
+				t.Fatalf("%s: cyk on %q: %v\n%s", r, w, err, out)
+			}
+			got := strings.Contains(string(out), "✓ SÍ")
+			if got != want {
+				t.Errorf("%s on %q: cyk says %v, NFA says %v\ngrammar:\n%s", r, strings.Join(w, ""), got, want, g)
+			}
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"lab4/nfa"
+	"lab4/ops"
+	"lab4/thompson"
+)
+
+// strs returns every string over alphabet of length 0..maxLen.
+func strs(alphabet string, maxLen int) []string {
+	out := []string{""}
+	level := []string{""}
+	for l := 1; l <= maxLen; l++ {
+		var next []string
+		for _, w := range level {
+			for _, a := range alphabet {
+				next = append(next, w+string(a))
+			}
+		}
+		out = append(out, next...)
+		level = next
+	}
+	return out
+}
+
+// lang returns the strings of length ≤ maxLen over alphabet accepted by n.
+func lang(n *thompson.NFA, alphabet string, maxLen int) []string {
+	var out []string
+	for _, w := range strs(alphabet, maxLen) {
+		if nfa.Simulate(n, w) {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// The operations are checked against their definitions on every string of
+// length ≤ 4 over {a, b, c}. Witnesses (the y of a prefix, the x of a suffix,
+// the other operand of a quotient) are searched up to length 4 too, which is
+// enough for these languages.
+const (
+	opsAlphabet = "abc"
+	opsMaxLen   = 4
+)
+
+func TestPrefixSuffixMembership(t *testing.T) {
+	for _, r := range []string{"abc", "a*b", "(ab)*", "a(b|c)*a", "ε|ca"} {
+		n := build(t, r)
+		pre, suf := ops.Prefix(n), ops.Suffix(n)
+		ext := strs(opsAlphabet, opsMaxLen)
+		for _, w := range strs(opsAlphabet, opsMaxLen) {
+			wantPre, wantSuf := false, false
+			for _, y := range ext {
+				wantPre = wantPre || nfa.Simulate(n, w+y)
+				wantSuf = wantSuf || nfa.Simulate(n, y+w)
+			}
+			if got := nfa.Simulate(pre, w); got != wantPre {
+				t.Errorf("Prefix(%s) on %q = %v, want %v", r, w, got, wantPre)
+			}
+			if got := nfa.Simulate(suf, w); got != wantSuf {
+				t.Errorf("Suffix(%s) on %q = %v, want %v", r, w, got, wantSuf)
+			}
+		}
+	}
+}
+
+func TestQuotientMembership(t *testing.T) {
+	pairs := []struct{ r1, r2 string }{
+		{"a*b", "b"},
+		{"a*bc", "c"},
+		{"a*bc", "a*b"},
+		{"(ab)*", "b"},
+		{"(ab)*", "(ab)*"},
+		{"abc", "a*b"},
+		{"a(b|c)*a", "ca|a"},
+		{"abc", "cc"}, // empty quotient
+	}
+	for _, p := range pairs {
+		n1, n2 := build(t, p.r1), build(t, p.r2)
+		right, left := ops.RightQuotient(n1, n2), ops.LeftQuotient(n1, n2)
+		l2 := lang(n2, opsAlphabet, opsMaxLen)
+		for _, w := range strs(opsAlphabet, opsMaxLen) {
+			wantRight, wantLeft := false, false
+			for _, v := range l2 {
+				wantRight = wantRight || nfa.Simulate(n1, w+v)
+				wantLeft = wantLeft || nfa.Simulate(n1, v+w)
+			}
+			if got := nfa.Simulate(right, w); got != wantRight {
+				t.Errorf("L(%s)/L(%s) on %q = %v, want %v", p.r1, p.r2, w, got, wantRight)
+			}
+			if got := nfa.Simulate(left, w); got != wantLeft {
+				t.Errorf("L(%s)\\L(%s) on %q = %v, want %v", p.r2, p.r1, w, got, wantLeft)
+			}
+		}
+	}
+}
+
+func TestHomomorphismMembership(t *testing.T) {
+	cases := []struct {
+		regex, spec, out string // out is the alphabet of the image
+	}{
+		{"(ab)*c", "a->xy, b->ε, c->c", "xyc"},
+		{"a(b|c)*", "a->x, b->yy, c->epsilon", "xy"},
+		{"e*b", "e->x, b->e", "xe"}, // e is a plain letter
+		{"(a|b)*", "a->b, b->a", "ab"},
+	}
+	for _, c := range cases {
+		h, err := ops.ParseHomomorphism(c.spec)
+		if err != nil {
+			t.Fatalf("ParseHomomorphism(%q): %v", c.spec, err)
+		}
+		n := build(t, c.regex)
+		img := h.Apply(n)
+
+		// h(L) restricted to short strings, from the preimages up to length 6
+		want := make(map[string]bool)
+		for _, v := range lang(n, "abce", 6) {
+			var b strings.Builder
+			for _, r := range v {
+				sym, ok := h[string(r)]
+				if !ok {
+					sym = []string{string(r)}
+				}
+				b.WriteString(strings.Join(sym, ""))
+			}
+			want[b.String()] = true
+		}
+		for _, w := range strs(c.out, 3) {
+			if got := nfa.Simulate(img, w); got != want[w] {
+				t.Errorf("h(L(%s)) with %s on %q = %v, want %v", c.regex, c.spec, w, got, want[w])
+			}
+		}
+	}
+}
+
+func TestParseHomomorphism(t *testing.T) {
+	cases := []struct {
+		spec string
+		want ops.Homomorphism
+	}{
+		{"a->xy, b->ε", ops.Homomorphism{"a": {"x", "y"}, "b": nil}},
+		{"a->epsilon", ops.Homomorphism{"a": nil}},
+		{"a->", ops.Homomorphism{"a": nil}},
+		{"e->x", ops.Homomorphism{"e": {"x"}}},
+		{"b->e", ops.Homomorphism{"b": {"e"}}},
+		{"<id>->x <id>", ops.Homomorphism{"id": {"x", "id"}}},
+	}
+	for _, c := range cases {
+		got, err := ops.ParseHomomorphism(c.spec)
+		if err != nil {
+			t.Errorf("ParseHomomorphism(%q): %v", c.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("ParseHomomorphism(%q) = %v, want %v", c.spec, got, c.want)
+		}
+	}
+	for _, bad := range []string{"", "a", "ab->x", "a->x, a->y", "<a>->x"} {
+		if _, err := ops.ParseHomomorphism(bad); err == nil {
+			t.Errorf("ParseHomomorphism(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestSplitLine(t *testing.T) {
+	cases := []struct {
+		raw, r, w, op string
+		ok            bool
+	}{
+		{"a(a|b)*;ab", "a(a|b)*", "ab", "", true},
+		{"abc;cba;reverse", "abc", "cba", "reverse", true},
+		{"a*b;aa; rquot:b ", "a*b", "aa", "rquot:b", true},
+		{"(ab)*;xyxy;hom:a->xy,b->ε", "(ab)*", "xyxy", "hom:a->xy,b->ε", true},
+		// a ';' inside w stays there unless an operation follows it
+		{"a;b;c", "a", "b;c", "", true},
+		{"x;y;z;w", "x", "y;z;w", "", true},
+		{"a;b;reversed", "a", "b;reversed", "", true},
+		{"a;;", "a", ";", "", true},
+		{"abc", "", "", "", false},
+	}
+	for _, c := range cases {
+		r, w, op, ok := ops.SplitLine(c.raw)
+		if r != c.r || w != c.w || op != c.op || ok != c.ok {
+			t.Errorf("SplitLine(%q) = %q, %q, %q, %v, want %q, %q, %q, %v",
+				c.raw, r, w, op, ok, c.r, c.w, c.op, c.ok)
+		}
+	}
+}
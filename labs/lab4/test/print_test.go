@@ -0,0 +1,76 @@
+package test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"lab4/config"
+	"lab4/regex"
+)
+
+func TestPrinters(t *testing.T) {
+	cases := []struct {
+		regex, infix, postfix, sexpr string
+	}{
+		{"a(b|c)*", "a(b|c)*", "abc|*.", "(concat a (star (union b c)))"},
+		{"((a))b", "ab", "ab.", "(concat a b)"},
+		{"a(bc)", "a(bc)", "abc..", "(concat a (concat b c))"},
+		{"(a|b)|c", "a|b|c", "ab|c|", "(union (union a b) c)"},
+		{"a|(b|c)", "a|(b|c)", "abc||", "(union a (union b c))"},
+		{"(ab)*|ε", "(ab)*|ε", "ab.*ε|", "(union (star (concat a b)) ε)"},
+		{`<id>\d\*`, `<id>\d\*`, `<id>\d.\*.`, `(concat (concat <id> \d) \*)`},
+	}
+	for _, c := range cases {
+		postfix := config.InfixToPostfixTo(io.Discard, config.FormatRegex(config.ExpandRegexExtensions(c.regex)))
+		tree, err := regex.BuildAST(postfix)
+		if err != nil {
+			t.Fatalf("BuildAST(%q): %v", postfix, err)
+		}
+		if got := tree.Infix(); got != c.infix {
+			t.Errorf("%s: Infix = %q, want %q", c.regex, got, c.infix)
+		}
+		if got := tree.Postfix(); got != c.postfix {
+			t.Errorf("%s: Postfix = %q, want %q", c.regex, got, c.postfix)
+		}
+		if got := tree.SExpr(); got != c.sexpr {
+			t.Errorf("%s: SExpr = %q, want %q", c.regex, got, c.sexpr)
+		}
+	}
+}
+
+func TestInfixToPostfixSteps(t *testing.T) {
+	postfix, steps := config.InfixToPostfixSteps("a.(b|c)*")
+	if postfix != "abc|*." {
+		t.Fatalf("postfix = %q, want %q", postfix, "abc|*.")
+	}
+
+	var kinds []string
+	for _, st := range steps {
+		kinds = append(kinds, st.Kind.String()+" "+st.Symbol)
+	}
+	want := []string{
+		"operando a",
+		"operador .", "push .",
+		"push (",
+		"operando b",
+		"operador |", "push |",
+		"operando c",
+		"cierre )", "pop |", "descarta ( (",
+		"operador *", "push *",
+		"fin ", "pop *", "pop .",
+	}
+	if strings.Join(kinds, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("steps:\n%s\nwant:\n%s", strings.Join(kinds, "\n"), strings.Join(want, "\n"))
+	}
+	if last := steps[len(steps)-1]; last.Output != postfix || last.Stack != "" {
+		t.Errorf("last step has output %q and stack %q", last.Output, last.Stack)
+	}
+
+	// the stdout trace is rendered from the same steps
+	var b strings.Builder
+	config.InfixToPostfixTo(&b, "a.(b|c)*")
+	if !strings.Contains(b.String(), "  Pop '|' → output = abc|\n") {
+		t.Errorf("trace lacks the pop of '|':\n%s", b.String())
+	}
+}
@@ -0,0 +1,106 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lab4/repl"
+)
+
+// exec1 runs line in s and returns what it printed and whether the session
+// goes on.
+func exec1(s *repl.Session, line string) (string, bool) {
+	var out strings.Builder
+	more := s.Exec(line, &out)
+	return out.String(), more
+}
+
+func TestExecCommands(t *testing.T) {
+	dot := filepath.Join(t.TempDir(), "nfa.dot")
+	s := &repl.Session{}
+	steps := []struct {
+		line string
+		want []string // substrings of the output, in order
+	}{
+		{"abc", []string{"no regex set"}},
+		{":postfix", []string{"no regex set"}},
+		{":regex", []string{"no regex set"}},
+		{":regex a(b|c)*", []string{"regex set: a(b|c)*"}},
+		{":regex", []string{"current regex: a(b|c)*"}},
+		{"abcb", []string{"sí", `"abcb"`}},
+		{"ba", []string{"no", `"ba"`}},
+		{"", nil},
+		{":postfix", []string{"expanded  : a(b|c)*", "formatted : a.(b|c)*", "postfix   : abc|*."}},
+		{":ast", []string{"infix : a(b|c)*", "sexpr : (concat a (star (union b c)))", "Concat", "Literal a", "Star", "Union"}},
+		{":steps", []string{"a", "|", "abc|*."}},
+		{":stats", []string{"states", "accepting   : 1", "alphabet    : 3 symbols"}},
+		{":dot", []string{"usage: :dot <file>"}},
+		{":dot " + dot, []string{"DOT saved: " + dot}},
+		{":regex a(|b", []string{"error:"}},
+		{"a", []string{"sí"}}, // a failed :regex keeps the previous one
+		{":tokens maybe", []string{"usage: :tokens on|off"}},
+		{":regex <id>(<comma><id>)*", []string{"regex set"}},
+		{":tokens on", []string{"token mode: on"}},
+		{"id comma id", []string{"sí"}},
+		{"id comma", []string{"no"}},
+		{":tokens off", []string{"token mode: off"}},
+		{":nope", []string{"unknown command :nope"}},
+		{":help", []string{":ast", ":quit", ":regex <r>", ":steps", "any other line"}},
+	}
+	for _, st := range steps {
+		got, more := exec1(s, st.line)
+		if !more {
+			t.Fatalf("%q ended the session", st.line)
+		}
+		rest := got
+		for _, w := range st.want {
+			i := strings.Index(rest, w)
+			if i < 0 {
+				t.Errorf("%q printed %q, missing %q", st.line, got, w)
+				break
+			}
+			rest = rest[i+len(w):]
+		}
+		if st.want == nil && got != "" {
+			t.Errorf("%q printed %q, want nothing", st.line, got)
+		}
+	}
+	if _, err := os.Stat(dot); err != nil {
+		t.Errorf(":dot did not write the file: %v", err)
+	}
+	for _, quit := range []string{":quit", ":q", "  :quit  "} {
+		if _, more := exec1(s, quit); more {
+			t.Errorf("%q did not end the session", quit)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	cases := []struct {
+		line    string
+		pos     int
+		key     rune
+		newLine string
+		newPos  int
+		ok      bool
+	}{
+		{":re", 3, '\t', ":regex ", 7, true},
+		{":s", 2, '\t', ":st", 3, true}, // :stats and :steps
+		{":st", 3, '\t', ":st", 3, true},
+		{":q", 2, '\t', ":quit ", 6, true},
+		{":rex", 2, '\t', ":regex ex", 7, true}, // completes the text before the cursor
+		{":x", 2, '\t', "", 0, false},
+		{"abc", 3, '\t', "", 0, false},      // not a command
+		{":regex a", 8, '\t', "", 0, false}, // past the command name
+		{":re", 3, 'x', "", 0, false},       // not Tab
+	}
+	for _, c := range cases {
+		line, pos, ok := repl.Complete(c.line, c.pos, c.key)
+		if line != c.newLine || pos != c.newPos || ok != c.ok {
+			t.Errorf("Complete(%q, %d, %q) = %q, %d, %v, want %q, %d, %v",
+				c.line, c.pos, c.key, line, pos, ok, c.newLine, c.newPos, c.ok)
+		}
+	}
+}
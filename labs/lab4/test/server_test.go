@@ -0,0 +1,140 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"lab4/server"
+)
+
+// post sends body to path on a fresh handler and returns the recorded reply.
+func post(t *testing.T, l server.Limits, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.NewHandler(l).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCompileJSON(t *testing.T) {
+	rec := post(t, server.DefaultLimits, "/compile", `{"regex": "a(b|c)*"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	var resp server.CompileResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Postfix != "abc|*." {
+		t.Errorf("postfix = %q, want %q", resp.Postfix, "abc|*.")
+	}
+	if resp.AST == nil || resp.AST.Kind != "concat" || resp.AST.Right.Kind != "star" {
+		t.Errorf("unexpected AST: %+v", resp.AST)
+	}
+	if resp.NFA == nil || len(resp.NFA.States) != 10 || len(resp.NFA.Accepts) != 1 {
+		t.Fatalf("unexpected NFA: %+v", resp.NFA)
+	}
+	if resp.DOT != "" {
+		t.Error("DOT should be empty for format=json")
+	}
+}
+
+func TestCompileDOT(t *testing.T) {
+	rec := post(t, server.DefaultLimits, "/compile", `{"regex": "ab", "format": "dot"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	var resp server.CompileResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !strings.HasPrefix(resp.DOT, "digraph NFA {") || resp.NFA != nil {
+		t.Errorf("expected only DOT output, got DOT=%q NFA=%+v", resp.DOT, resp.NFA)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	rec := post(t, server.DefaultLimits, "/match", `{"regex": "a(a|b)*abb", "strings": ["aaaaabb", "ababab"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	var resp server.MatchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := []bool{true, false}
+	if len(resp.Results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(resp.Results), len(want))
+	}
+	for i, r := range resp.Results {
+		if r.Accepted != want[i] {
+			t.Errorf("%q: accepted = %v, want %v", r.Input, r.Accepted, want[i])
+		}
+	}
+}
+
+func TestMatchTokens(t *testing.T) {
+	rec := post(t, server.DefaultLimits, "/match",
+		`{"regex": "<id>(<comma><id>)*", "strings": ["id comma id", "id comma"], "tokens": true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	var resp server.MatchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.Results[0].Accepted || resp.Results[1].Accepted {
+		t.Errorf("unexpected verdicts: %+v", resp.Results)
+	}
+}
+
+func TestRejectedRequests(t *testing.T) {
+	small := server.DefaultLimits
+	small.MaxBodyBytes = 64
+
+	tests := []struct {
+		name   string
+		limits server.Limits
+		path   string
+		body   string
+		status int
+	}{
+		{"invalid JSON", server.DefaultLimits, "/compile", `{"regex":`, http.StatusBadRequest},
+		{"unknown field", server.DefaultLimits, "/compile", `{"regexp": "a"}`, http.StatusBadRequest},
+		{"empty regex", server.DefaultLimits, "/compile", `{"regex": ""}`, http.StatusBadRequest},
+		{"malformed regex", server.DefaultLimits, "/compile", `{"regex": "a|"}`, http.StatusBadRequest},
+		{"unknown format", server.DefaultLimits, "/compile", `{"regex": "a", "format": "svg"}`, http.StatusBadRequest},
+		{"regex too long", server.DefaultLimits, "/compile", `{"regex": "` + strings.Repeat("a", 300) + `"}`, http.StatusBadRequest},
+		{"too many repeats", server.DefaultLimits, "/compile", `{"regex": "` + strings.Repeat("(", 12) + "a" + strings.Repeat(")+", 12) + `"}`, http.StatusBadRequest},
+		{"body too large", small, "/match", `{"regex": "a", "strings": ["` + strings.Repeat("a", 100) + `"]}`, http.StatusRequestEntityTooLarge},
+		{"no strings", server.DefaultLimits, "/match", `{"regex": "a", "strings": []}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := post(t, tt.limits, tt.path, tt.body)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.status, rec.Body)
+			}
+			var e struct{ Error string }
+			if err := json.Unmarshal(rec.Body.Bytes(), &e); err != nil || e.Error == "" {
+				t.Errorf("expected a JSON error body, got %s", rec.Body)
+			}
+		})
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/match", nil)
+	rec := httptest.NewRecorder()
+	server.NewHandler(server.DefaultLimits).ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
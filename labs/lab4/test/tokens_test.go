@@ -0,0 +1,110 @@
+package test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lab4/config"
+	"lab4/graphviz"
+	"lab4/nfa"
+	"lab4/regex"
+)
+
+func TestReadToken(t *testing.T) {
+	cases := []struct {
+		in   string
+		name string
+		ok   bool
+	}{
+		{"<id>", "id", true},
+		{"<a:x>", "a:x", true},
+		{"<b:ε>", "b:ε", true},
+		{"<num_2>rest", "num_2", true},
+		{"<ab>", "ab", true},
+		{"<a>", "", false}, // would collide with the literal a
+		{"<ε>", "", false}, // would be an ε-transition
+		{"<>", "", false},
+		{"<id", "", false},
+		{"<i d>", "", false},
+		{"id>", "", false},
+	}
+	for _, c := range cases {
+		name, next, ok := config.ReadToken([]rune(c.in), 0)
+		if ok != c.ok || name != c.name {
+			t.Errorf("ReadToken(%q) = %q, %v, want %q, %v", c.in, name, ok, c.name, c.ok)
+		}
+		if n := len([]rune(c.name)) + 2; ok && next != n {
+			t.Errorf("ReadToken(%q) next = %d, want %d", c.in, next, n)
+		}
+	}
+}
+
+func TestSingleRuneTokensRejected(t *testing.T) {
+	for _, r := range []string{"<a>", "<ε>", "<id><a>", "<ε>|a"} {
+		postfix := config.InfixToPostfixTo(io.Discard, config.FormatRegex(config.ExpandRegexExtensions(r)))
+		if _, err := regex.BuildAST(postfix); err == nil {
+			t.Errorf("BuildAST accepted %s", r)
+		}
+	}
+}
+
+func TestSimulateTokens(t *testing.T) {
+	n := build(t, "<id>(<comma><id>)*")
+	cases := []struct {
+		w    string
+		want bool
+	}{
+		{"id", true},
+		{"id comma id", true},
+		{"id comma id comma id", true},
+		{"", false},
+		{"id comma", false},
+		{"id id", false},
+		{"i d", false}, // the runes of a name are not symbols of their own
+	}
+	for _, c := range cases {
+		if got := nfa.SimulateTokens(n, strings.Fields(c.w)); got != c.want {
+			t.Errorf("<id>(<comma><id>)* on %q = %v, want %v", c.w, got, c.want)
+		}
+	}
+
+	// a token and a literal with similar spelling stay distinct
+	mixed := build(t, "<ab>|a")
+	for w, want := range map[string]bool{"ab": true, "a": true, "a b": false, "b": false} {
+		if got := nfa.SimulateTokens(mixed, strings.Fields(w)); got != want {
+			t.Errorf("<ab>|a on %q = %v, want %v", w, got, want)
+		}
+	}
+}
+
+func TestTokensRoundTrip(t *testing.T) {
+	for _, r := range []string{"<id>(<comma><id>)*", "<ab>|a", "(<x1>|b)*<end>"} {
+		postfix := config.InfixToPostfixTo(io.Discard, config.FormatRegex(config.ExpandRegexExtensions(r)))
+		ast, err := regex.BuildAST(postfix)
+		if err != nil {
+			t.Fatalf("BuildAST(%q): %v", postfix, err)
+		}
+		if got := ast.Infix(); got != r {
+			t.Errorf("Infix = %q, want %q", got, r)
+		}
+	}
+}
+
+func TestTokensInDOT(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.dot")
+	if err := graphviz.WriteDOT(build(t, "<id>a"), path); err != nil {
+		t.Fatal(err)
+	}
+	dot, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, label := range []string{`label="<id>"`, `label="a"`} {
+		if !strings.Contains(string(dot), label) {
+			t.Errorf("DOT has no edge %s:\n%s", label, dot)
+		}
+	}
+}
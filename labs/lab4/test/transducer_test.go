@@ -0,0 +1,71 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"lab4/transducer"
+)
+
+// ToMoore must produce exactly the translations of the Mealy transducer on
+// every input of length ≤ 4.
+func TestMooreEquivalentToMealy(t *testing.T) {
+	cases := []struct{ expr, alphabet string }{
+		{"(a:x|b:ε)*", "ab"},
+		{"(a:0|b:1)*c:ε", "abc"},
+		{"(a:x|a:y)b", "ab"}, // nondeterministic: two outputs for "ab"
+		{"(a:1b:0)*|a:x", "ab"},
+		{"a(b:y|ε:ε)c", "abc"}, // ε:ε is a plain ε-move
+		{"(ε:ε|a:x)*b:y", "ab"},
+	}
+	for _, c := range cases {
+		mealy, err := transducer.Build(c.expr)
+		if err != nil {
+			t.Fatalf("Build(%q): %v", c.expr, err)
+		}
+		moore, err := mealy.ToMoore()
+		if err != nil {
+			t.Fatalf("%s: ToMoore: %v", c.expr, err)
+		}
+		for _, w := range strs(c.alphabet, 4) {
+			want, err := mealy.TranslateString(w)
+			if err != nil {
+				t.Fatalf("%s: Mealy on %q: %v", c.expr, w, err)
+			}
+			got, err := moore.TranslateString(w)
+			if err != nil {
+				t.Fatalf("%s: Moore on %q: %v", c.expr, w, err)
+			}
+			if len(want) == 0 && len(got) == 0 {
+				continue
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s on %q: Moore gives %q, Mealy gives %q", c.expr, w, got, want)
+			}
+		}
+	}
+}
+
+func TestMealyTranslate(t *testing.T) {
+	mealy, err := transducer.Build("(a:x|a:y)b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := mealy.TranslateString("ab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"xb", "yb"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("(a:x|a:y)b on \"ab\" = %q, want %q", got, want)
+	}
+}
+
+func TestToMooreRejectsOutputWithoutInput(t *testing.T) {
+	mealy, err := transducer.Build("a:xε:y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mealy.ToMoore(); err == nil {
+		t.Error("ToMoore accepted ε:y")
+	}
+}
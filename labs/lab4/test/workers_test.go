@@ -0,0 +1,95 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// fakeDot stands in for Graphviz: it prints to stdout and stderr, as dot does
+// with warnings, and creates the PNG.
+const fakeDot = `#!/bin/sh
+echo "dot: warning in $2"
+sleep 0.01
+echo "dot: second warning in $2" >&2
+touch "$4"
+`
+
+// timestamps matches the log.LstdFlags prefix, which differs between runs.
+var timestamps = regexp.MustCompile(`(?m)^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} `)
+
+// runBatch runs the lab4 binary in batch mode with the given number of
+// workers and returns what it printed to stdout and stderr.
+func runBatch(t *testing.T, bin, dir string, workers int) (string, string) {
+	t.Helper()
+	cmd := exec.Command(bin, "-in", filepath.Join(dir, "input.txt"),
+		"-dotout", filepath.Join(dir, "dot"), "-pngout", filepath.Join(dir, "png"),
+		"-workers", fmt.Sprint(workers))
+	cmd.Env = append(os.Environ(), "PATH="+filepath.Join(dir, "bin")+string(os.PathListSeparator)+os.Getenv("PATH"))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("-workers=%d: %v\n%s", workers, err, stderr.String())
+	}
+	return stdout.String(), timestamps.ReplaceAllString(stderr.String(), "")
+}
+
+// With -workers, each line's report, including what dot prints, must come
+// out whole and in input order, exactly as with a single worker.
+func TestWorkersOutputMatchesSequential(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs the lab4 binary")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not in PATH")
+	}
+	dir := t.TempDir()
+	for _, sub := range []string{"bin", "dot", "png"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bin := filepath.Join(dir, "bin", "lab4")
+	build := exec.Command(goTool, "build", "-o", bin, ".")
+	build.Dir = ".."
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("building lab4: %v\n%s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bin", "dot"), []byte(fakeDot), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	lines := []string{
+		"a(a|b)*abb;aaaaabb",
+		"a(a|b)*abb;ababab",
+		"abc;cba;reverse",
+		"a*b;aa;rquot:b",
+		"(ab)*;xyxy;hom:a->xy,b->ε",
+		"a(|b;ab", // malformed
+		"\\d+(\\s\\d+)*;12 7 2024",
+		"(a|b)*c;ababc;prefix",
+	}
+	input := strings.Join(append(lines, lines...), "\n") + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "input.txt"), []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	wantOut, wantErr := runBatch(t, bin, dir, 1)
+	if !strings.Contains(wantErr, "dot: warning in") {
+		t.Fatalf("dot's output is missing from the report:\n%s", wantErr)
+	}
+	for range 3 {
+		gotOut, gotErr := runBatch(t, bin, dir, 4)
+		if gotOut != wantOut {
+			t.Fatalf("-workers=4 stdout differs from -workers=1\n--- workers=4\n%s\n--- workers=1\n%s", gotOut, wantOut)
+		}
+		if gotErr != wantErr {
+			t.Fatalf("-workers=4 stderr differs from -workers=1\n--- workers=4\n%s\n--- workers=1\n%s", gotErr, wantErr)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package thompson
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// EpsilonClosure returns the states reachable from s using only
+// ε-transitions, including s itself, sorted by ID.
+func EpsilonClosure(s *State) []*State {
+	seen := map[*State]bool{s: true}
+	stack := []*State{s}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, nxt := range cur.Trans[Epsilon] {
+			if !seen[nxt] {
+				seen[nxt] = true
+				stack = append(stack, nxt)
+			}
+		}
+	}
+
+	out := make([]*State, 0, len(seen))
+	for st := range seen {
+		out = append(out, st)
+	}
+	sortStates(out)
+	return out
+}
+
+// EliminateEpsilon returns an equivalent NFA without ε-transitions.
+// Each state p keeps its ID and gets p --a--> r for every r reachable by
+// reading a from some state in the ε-closure of p; p accepts if its closure
+// contains an accept state. States unreachable from the start are pruned.
+// The input NFA is left unchanged.
+func EliminateEpsilon(n *NFA) *NFA {
+	copies := make(map[*State]*State, len(n.States))
+	for _, s := range n.States {
+		copies[s] = &State{ID: s.ID, Trans: make(map[string][]*State)}
+	}
+
+	var accepts []*State
+	for _, s := range n.States {
+		p := copies[s]
+		added := make(map[string]map[*State]bool)
+		accepting := false
+
+		for _, q := range EpsilonClosure(s) {
+			if n.IsAccept(q) {
+				accepting = true
+			}
+			for sym, outs := range q.Trans {
+				if sym == Epsilon {
+					continue
+				}
+				if added[sym] == nil {
+					added[sym] = make(map[*State]bool)
+				}
+				for _, r := range outs {
+					if !added[sym][r] {
+						added[sym][r] = true
+						p.Trans[sym] = append(p.Trans[sym], copies[r])
+					}
+				}
+			}
+		}
+		if accepting {
+			accepts = append(accepts, p)
+		}
+	}
+
+	// prune states no longer reachable from the start
+	start := copies[n.Start]
+	reachable := map[*State]bool{start: true}
+	stack := []*State{start}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, outs := range cur.Trans {
+			for _, t := range outs {
+				if !reachable[t] {
+					reachable[t] = true
+					stack = append(stack, t)
+				}
+			}
+		}
+	}
+
+	out := &NFA{Start: start}
+	for st := range reachable {
+		out.States = append(out.States, st)
+	}
+	sortStates(out.States)
+	for _, a := range accepts {
+		if reachable[a] {
+			out.Accepts = append(out.Accepts, a)
+		}
+	}
+	sortStates(out.Accepts)
+	if len(out.Accepts) == 1 {
+		out.Accept = out.Accepts[0]
+	}
+	return out
+}
+
+// WriteClosureTable writes the ε-closure of every state of n, one per line,
+// marking the states whose closure contains an accept state.
+func WriteClosureTable(w io.Writer, n *NFA) {
+	states := append([]*State(nil), n.States...)
+	sortStates(states)
+	for _, s := range states {
+		closure := EpsilonClosure(s)
+		names := make([]string, len(closure))
+		mark := ""
+		for i, q := range closure {
+			names[i] = fmt.Sprintf("q%d", q.ID)
+			if n.IsAccept(q) {
+				mark = "  (accept)"
+			}
+		}
+		fmt.Fprintf(w, "    ε-closure(q%d) = {%s}%s\n", s.ID, strings.Join(names, ", "), mark)
+	}
+}
+
+// sortStates sorts states by ID in place.
+func sortStates(states []*State) {
+	sort.Slice(states, func(i, j int) bool { return states[i].ID < states[j].ID })
+}
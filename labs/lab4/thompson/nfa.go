@@ -8,19 +8,35 @@ import (
 	"lab4/regex"
 )
 
-const Epsilon rune = 'ε'
+// Epsilon labels ε-transitions.
+const Epsilon = "ε"
 
 // State represents a state in the NFA.
+// Trans is keyed by symbol: a single rune such as "a" or a token name such as "id".
 type State struct {
 	ID    int
-	Trans map[rune][]*State
+	Trans map[string][]*State
 }
 
 // NFA represents a non-deterministic finite automaton.
+// Accept is the single accept state produced by Thompson's construction; it is
+// nil for automata with several accept states, such as those returned by
+// EliminateEpsilon. Accepts always lists every accept state.
 type NFA struct {
-	Start  *State
-	Accept *State
-	States []*State
+	Start   *State
+	Accept  *State
+	Accepts []*State
+	States  []*State
+}
+
+// IsAccept reports whether s is an accept state of the NFA.
+func (n *NFA) IsAccept(s *State) bool {
+	for _, a := range n.Accepts {
+		if a == s {
+			return true
+		}
+	}
+	return false
 }
 
 // builder helps in constructing the NFA.
@@ -30,14 +46,14 @@ type builder struct{ next int }
 func (b *builder) newState() *State {
 	s := &State{
 		ID:    b.next,
-		Trans: make(map[rune][]*State),
+		Trans: make(map[string][]*State),
 	}
 	b.next++
 	return s
 }
 
 // addEdge adds a transition from 'from' to 'to' on symbol 'sym'.
-func (b *builder) addEdge(from *State, sym rune, to *State) {
+func (b *builder) addEdge(from *State, sym string, to *State) {
 	from.Trans[sym] = append(from.Trans[sym], to)
 }
 
@@ -78,9 +94,10 @@ func Build(ast *regex.Node) (*NFA, error) {
 	}
 
 	return &NFA{
-		Start:  f.start,
-		Accept: f.accept,
-		States: states,
+		Start:   f.start,
+		Accept:  f.accept,
+		Accepts: []*State{f.accept},
+		States:  states,
 	}, nil
 }
 
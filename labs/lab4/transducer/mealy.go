@@ -0,0 +1,236 @@
+// Package transducer implements finite-state transducers (Mealy and Moore
+// machines) on top of the Thompson state graph. A transducer regex uses
+// "in:out" operands such as (a:x|b:ε)*; each pair is rewritten to the token
+// symbol "<in:out>" so the usual expand → format → postfix → AST → Thompson
+// pipeline builds the transition graph unchanged.
+package transducer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"lab4/config"
+	"lab4/regex"
+	"lab4/thompson"
+)
+
+// Mealy is a nondeterministic Mealy transducer. Transitions are the symbols
+// of a Thompson NFA labeled "in:out"; a label without ':' copies its input to
+// the output, and thompson.Epsilon is an ε:ε move.
+type Mealy struct {
+	NFA *thompson.NFA
+}
+
+// SplitLabel splits a transition label into its input and output symbols.
+func SplitLabel(label string) (in, out string) {
+	if i := strings.Index(label, ":"); i >= 0 {
+		return label[:i], label[i+1:]
+	}
+	return label, label
+}
+
+// readOperand reads a single operand at chars[i]: a token symbol such as
+// "<id>" or an alphanumeric rune. It returns the symbol and the index just
+// past it.
+func readOperand(chars []rune, i int) (string, int, bool) {
+	if name, next, ok := config.ReadToken(chars, i); ok {
+		return name, next, true
+	}
+	if i < len(chars) && config.IsAlphanumeric(chars[i]) {
+		return string(chars[i]), i + 1, true
+	}
+	return "", i, false
+}
+
+// Rewrite turns every "in:out" operand of a transducer regex into the token
+// symbol "<in:out>", e.g. "(a:x|b:ε)*" becomes "(<a:x>|<b:ε>)*". Operands
+// without ':' are left as they are and copy their input to the output.
+func Rewrite(expr string) (string, error) {
+	chars := []rune(expr)
+	var b strings.Builder
+	for i := 0; i < len(chars); {
+		in, next, ok := readOperand(chars, i)
+		if !ok {
+			if chars[i] == ':' {
+				return "", fmt.Errorf("':' at position %d has no input symbol", i)
+			}
+			b.WriteRune(chars[i])
+			i++
+			continue
+		}
+		if next >= len(chars) || chars[next] != ':' {
+			b.WriteString(string(chars[i:next]))
+			i = next
+			continue
+		}
+		out, after, ok := readOperand(chars, next+1)
+		if !ok {
+			return "", fmt.Errorf("missing output symbol after %q", in+":")
+		}
+		fmt.Fprintf(&b, "<%s:%s>", in, out)
+		i = after
+	}
+	return b.String(), nil
+}
+
+// Build compiles a transducer regex into a Mealy transducer.
+func Build(expr string) (*Mealy, error) {
+	rewritten, err := Rewrite(expr)
+	if err != nil {
+		return nil, err
+	}
+	postfix := config.InfixToPostfixTo(io.Discard, config.FormatRegex(config.ExpandRegexExtensions(rewritten)))
+	ast, err := regex.BuildAST(postfix)
+	if err != nil {
+		return nil, err
+	}
+	n, err := thompson.Build(ast)
+	if err != nil {
+		return nil, err
+	}
+	return &Mealy{NFA: n}, nil
+}
+
+// run is one path through the transducer: the current state, the output produced
+// so far, and how many symbols were output since the last input symbol.
+type run struct {
+	state *thompson.State
+	out   []string
+	grown int
+}
+
+// key identifies a run for deduplication.
+func (r run) key() string {
+	return fmt.Sprintf("%d|%s", r.state.ID, strings.Join(r.out, "\x1f"))
+}
+
+// appendOut returns out followed by sym, unless sym is ε.
+func appendOut(out []string, sym string) []string {
+	if sym == thompson.Epsilon {
+		return out
+	}
+	next := make([]string, len(out), len(out)+1)
+	copy(next, out)
+	return append(next, sym)
+}
+
+// closure follows every ε-input transition from runs. A run that outputs
+// more symbols than there are states without reading input must have gone
+// around a cycle that produces output, so the input has infinitely many
+// translations and an error is returned.
+func (m *Mealy) closure(runs []run) ([]run, error) {
+	seen := make(map[string]bool)
+	var out []run
+	stack := append([]run(nil), runs...)
+	for len(stack) > 0 {
+		r := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[r.key()] {
+			continue
+		}
+		seen[r.key()] = true
+		out = append(out, r)
+
+		for label, targets := range r.state.Trans {
+			in, o := SplitLabel(label)
+			if in != thompson.Epsilon {
+				continue
+			}
+			grown := r.grown
+			if o != thompson.Epsilon {
+				grown++
+			}
+			if grown > len(m.NFA.States) {
+				return nil, fmt.Errorf("infinitely many outputs: a cycle of ε-input transitions produces output")
+			}
+			for _, t := range targets {
+				stack = append(stack, run{state: t, out: appendOut(r.out, o), grown: grown})
+			}
+		}
+	}
+	return out, nil
+}
+
+// Translate returns every output symbol sequence the transducer can produce
+// while accepting input, sorted and without duplicates. An empty result means
+// the input is rejected.
+func (m *Mealy) Translate(input []string) ([][]string, error) {
+	runs, err := m.closure([]run{{state: m.NFA.Start}})
+	if err != nil {
+		return nil, err
+	}
+	for _, sym := range input {
+		var next []run
+		for _, r := range runs {
+			for label, targets := range r.state.Trans {
+				in, o := SplitLabel(label)
+				if in != sym {
+					continue
+				}
+				for _, t := range targets {
+					next = append(next, run{state: t, out: appendOut(r.out, o)})
+				}
+			}
+		}
+		if runs, err = m.closure(next); err != nil {
+			return nil, err
+		}
+	}
+
+	var accepted []run
+	for _, r := range runs {
+		if m.NFA.IsAccept(r.state) {
+			accepted = append(accepted, r)
+		}
+	}
+	return collectOutputs(accepted), nil
+}
+
+// TranslateString translates input read one rune per symbol and returns each
+// output with its symbols concatenated.
+func (m *Mealy) TranslateString(input string) ([]string, error) {
+	outs, err := m.Translate(Symbols(input))
+	if err != nil {
+		return nil, err
+	}
+	return joinOutputs(outs, ""), nil
+}
+
+// Symbols splits s into one symbol per rune.
+func Symbols(s string) []string {
+	out := make([]string, 0, utf8.RuneCountInString(s))
+	for _, r := range s {
+		out = append(out, string(r))
+	}
+	return out
+}
+
+// collectOutputs returns the distinct outputs of runs in sorted order.
+func collectOutputs(runs []run) [][]string {
+	seen := make(map[string]bool)
+	var outs [][]string
+	for _, r := range runs {
+		k := strings.Join(r.out, "\x1f")
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		outs = append(outs, r.out)
+	}
+	sort.Slice(outs, func(i, j int) bool {
+		return strings.Join(outs[i], "\x1f") < strings.Join(outs[j], "\x1f")
+	})
+	return outs
+}
+
+// joinOutputs joins the symbols of each output with sep.
+func joinOutputs(outs [][]string, sep string) []string {
+	res := make([]string, len(outs))
+	for i, o := range outs {
+		res[i] = strings.Join(o, sep)
+	}
+	return res
+}
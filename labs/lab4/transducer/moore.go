@@ -0,0 +1,162 @@
+package transducer
+
+import (
+	"fmt"
+	"sort"
+
+	"lab4/thompson"
+)
+
+// Moore is a nondeterministic Moore transducer. Transitions of NFA are
+// labeled with input symbols only; each state carries an output symbol,
+// emitted whenever the state is entered. States without an entry in Output
+// emit nothing.
+type Moore struct {
+	NFA    *thompson.NFA
+	Output map[*thompson.State]string
+}
+
+// OutputOf returns the output symbol of s, or ε if it has none.
+func (m *Moore) OutputOf(s *thompson.State) string {
+	if o, ok := m.Output[s]; ok {
+		return o
+	}
+	return thompson.Epsilon
+}
+
+// plainEpsilon returns n with every ε:ε label, which reads and writes
+// nothing, turned into a plain ε-move. n itself is left unchanged.
+func plainEpsilon(n *thompson.NFA) *thompson.NFA {
+	copies := make(map[*thompson.State]*thompson.State, len(n.States))
+	of := func(s *thompson.State) *thompson.State {
+		if c, ok := copies[s]; ok {
+			return c
+		}
+		c := &thompson.State{ID: s.ID, Trans: make(map[string][]*thompson.State)}
+		copies[s] = c
+		return c
+	}
+	out := &thompson.NFA{Start: of(n.Start)}
+	for _, s := range n.States {
+		c := of(s)
+		out.States = append(out.States, c)
+		for label, targets := range s.Trans {
+			if in, o := SplitLabel(label); in == thompson.Epsilon && o == thompson.Epsilon {
+				label = thompson.Epsilon
+			}
+			for _, t := range targets {
+				c.Trans[label] = append(c.Trans[label], of(t))
+			}
+		}
+	}
+	for _, a := range n.Accepts {
+		out.Accepts = append(out.Accepts, of(a))
+	}
+	if n.Accept != nil {
+		out.Accept = of(n.Accept)
+	}
+	return out
+}
+
+// ToMoore converts the Mealy transducer into an equivalent Moore machine.
+// ε-moves, including ε:ε transitions, are eliminated first; each remaining
+// state q is then split into one state (q, o) per output o on the
+// transitions entering q, with the start state emitting nothing. Transitions
+// that output without reading input (ε:x) have no Moore equivalent and are
+// reported as an error.
+func (m *Mealy) ToMoore() (*Moore, error) {
+	src := thompson.EliminateEpsilon(plainEpsilon(m.NFA))
+	for _, s := range src.States {
+		for label := range s.Trans {
+			if in, out := SplitLabel(label); in == thompson.Epsilon {
+				return nil, fmt.Errorf("transition ε:%s outputs without reading input; no Moore equivalent", out)
+			}
+		}
+	}
+
+	type key struct {
+		state *thompson.State
+		out   string
+	}
+	moore := &Moore{Output: make(map[*thompson.State]string)}
+	states := make(map[key]*thompson.State)
+	var queue []key
+	get := func(k key) *thompson.State {
+		if s, ok := states[k]; ok {
+			return s
+		}
+		s := &thompson.State{ID: len(states), Trans: make(map[string][]*thompson.State)}
+		states[k] = s
+		if k.out != thompson.Epsilon {
+			moore.Output[s] = k.out
+		}
+		queue = append(queue, k)
+		return s
+	}
+
+	n := &thompson.NFA{Start: get(key{src.Start, thompson.Epsilon})}
+	for len(queue) > 0 {
+		k := queue[0]
+		queue = queue[1:]
+		from := states[k]
+		n.States = append(n.States, from)
+		if src.IsAccept(k.state) {
+			n.Accepts = append(n.Accepts, from)
+		}
+
+		labels := make([]string, 0, len(k.state.Trans))
+		for label := range k.state.Trans {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+		for _, label := range labels {
+			in, out := SplitLabel(label)
+			for _, t := range k.state.Trans[label] {
+				from.Trans[in] = append(from.Trans[in], get(key{t, out}))
+			}
+		}
+	}
+	if len(n.Accepts) == 1 {
+		n.Accept = n.Accepts[0]
+	}
+	moore.NFA = n
+	return moore, nil
+}
+
+// Translate returns every output symbol sequence the Moore machine can
+// produce while accepting input, sorted and without duplicates.
+func (m *Moore) Translate(input []string) ([][]string, error) {
+	runs := []run{{state: m.NFA.Start}}
+	for _, sym := range input {
+		seen := make(map[string]bool)
+		var next []run
+		for _, r := range runs {
+			for _, t := range r.state.Trans[sym] {
+				nr := run{state: t, out: appendOut(r.out, m.OutputOf(t))}
+				if !seen[nr.key()] {
+					seen[nr.key()] = true
+					next = append(next, nr)
+				}
+			}
+		}
+		runs = next
+	}
+
+	var accepted []run
+	for _, r := range runs {
+		if m.NFA.IsAccept(r.state) {
+			accepted = append(accepted, r)
+		}
+	}
+	return collectOutputs(accepted), nil
+}
+
+// TranslateString translates input read one rune per symbol and returns each
+// output with its symbols concatenated.
+func (m *Moore) TranslateString(input string) ([]string, error) {
+	outs, err := m.Translate(Symbols(input))
+	if err != nil {
+		return nil, err
+	}
+	return joinOutputs(outs, ""), nil
+}
@@ -1,38 +1,252 @@
 package config
 
 import (
+	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// TimeN mide r(n) con DefaultTimeOptions y al menos runs repeticiones.
 func TimeN(r Runner, n, runs int) Result {
-	if runs < 1 {
-		runs = 1
+	opts := DefaultTimeOptions
+	opts.MinRuns = runs
+	return TimeNWithOpts(r, n, opts)
+}
+
+// TimeNWithOpts hace opts.Warmup corridas sin medir y luego mide al menos
+// opts.MinRuns. Si opts.RelErr > 0 sigue midiendo hasta que el intervalo de
+// confianza del 95% sea menor que RelErr·media, hasta opts.MaxRuns corridas
+// o hasta gastar opts.MaxBudget, lo que ocurra primero.
+func TimeNWithOpts(r Runner, n int, opts TimeOptions) Result {
+	res, _ := TimeNContext(context.Background(), r.WithContext(), n, opts)
+	return res
+}
+
+// TimeNContext es TimeNWithOpts con cancelación: si ctx termina antes (por
+// ejemplo por un plazo por tamaño), la corrida en curso se descarta, Note
+// queda en "timeout" o "canceled" y se devuelve ctx.Err(). Las
+// estadísticas son las de las corridas que sí terminaron (Runs puede ser 0);
+// si lo que se corta es la corrida extra de opts.Memory, Mem queda en nil.
+func TimeNContext(ctx context.Context, r ContextRunner, n int, opts TimeOptions) (Result, error) {
+	if opts.MinRuns < 1 {
+		opts.MinRuns = 1
+	}
+	if opts.MaxRuns < opts.MinRuns {
+		opts.MaxRuns = opts.MinRuns
 	}
-	var total time.Duration
 	var sink uint64
-	for i := 0; i < runs; i++ {
+	var runErr error
+	for i := 0; i < opts.Warmup && runErr == nil; i++ {
+		var v uint64
+		v, runErr = r(ctx, n)
+		sink += v
+	}
+
+	var notes []string
+	stopCPU := func() {}
+	if opts.ProfilePrefix != "" && runErr == nil {
+		stop, err := startCPUProfile(opts.ProfilePrefix + ".cpu.pprof")
+		if err != nil {
+			notes = append(notes, "cpu profile: "+err.Error())
+		} else {
+			stopCPU = stop
+		}
+	}
+
+	var samples []float64 // ms
+	var total time.Duration
+	var ops uint64
+	var mem memCounter
+	for runErr == nil && len(samples) < opts.MaxRuns {
+		if opts.Memory {
+			mem.before()
+		}
 		start := time.Now()
-		sink += r(n)
-		total += time.Since(start)
+		v, err := r(ctx, n)
+		d := time.Since(start)
+		if err != nil {
+			runErr = err
+			break
+		}
+		if opts.Memory {
+			mem.after()
+		}
+		ops = v
+		sink += ops
+		total += d
+		// PRECISIÓN: usa ns -> ms con decimales (no Duration.Milliseconds())
+		samples = append(samples, float64(d.Nanoseconds())/1e6)
+
+		if len(samples) < opts.MinRuns {
+			continue
+		}
+		if opts.RelErr <= 0 || (opts.MaxBudget > 0 && total >= opts.MaxBudget) {
+			break
+		}
+		if st := Summarize(samples); st.CI95Ms <= opts.RelErr*st.AvgMs {
+			break
+		}
 	}
+	stopCPU()
+
+	res := Summarize(samples)
+	res.N = n
+	res.Warmup = opts.Warmup
+	res.Ops = ops
+	if runErr == nil && opts.Memory {
+		peak, out, err := peakHeap(ctx, r, n)
+		sink += out
+		if err != nil {
+			runErr = err
+		} else {
+			res.Mem = mem.result(peak)
+		}
+	}
+	if runErr != nil {
+		notes = append([]string{interruptNote(runErr)}, notes...)
+	} else {
+		if opts.ProfilePrefix != "" {
+			if err := writeHeapProfile(opts.ProfilePrefix + ".heap.pprof"); err != nil {
+				notes = append(notes, "heap profile: "+err.Error())
+			}
+		}
+	}
+	res.Note = strings.Join(notes, "; ")
+
 	// Evita que el compilador elimine el trabajo
 	if sink == math.MaxUint64 {
 		fmt.Fprintln(os.Stderr, "ignore:", sink)
 	}
-	// PRECISIÓN: usa ns -> ms con decimales (no Duration.Milliseconds())
-	avgMs := (float64(total.Nanoseconds()) / 1e6) / float64(runs)
+	return res, runErr
+}
+
+// interruptNote es lo que va a la columna note cuando ctx corta la medición.
+// Si la corrida quedó abandonada (AbandonOnCancel) se agrega
+// "abandoned run": las filas siguientes pueden salir más lentas.
+func interruptNote(err error) string {
+	note := err.Error()
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		note = "timeout"
+	case errors.Is(err, context.Canceled):
+		note = "canceled"
+	}
+	if errors.Is(err, ErrAbandoned) {
+		note += "; abandoned run"
+	}
+	return note
+}
+
+// Summarize calcula las estadísticas de Result a partir de los tiempos en ms
+// (N, Warmup, Ops y Note quedan vacíos).
+func Summarize(samples []float64) Result {
+	k := len(samples)
+	if k == 0 {
+		return Result{}
+	}
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
 
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	avg := sum / float64(k)
+	var ss float64
+	for _, v := range sorted {
+		ss += (v - avg) * (v - avg)
+	}
+	var std, ci float64
+	if k > 1 {
+		std = math.Sqrt(ss / float64(k-1))
+		ci = tQuantile95(k-1) * std / math.Sqrt(float64(k))
+	}
 	return Result{
-		N:     n,
-		AvgMs: avgMs,
-		Runs:  runs,
-		Note:  "",
+		AvgMs:    avg,
+		Runs:     k,
+		MinMs:    sorted[0],
+		MedianMs: percentile(sorted, 0.5),
+		P95Ms:    percentile(sorted, 0.95),
+		StdMs:    std,
+		CI95Ms:   ci,
+	}
+}
+
+// percentile interpola linealmente entre los valores ordenados.
+func percentile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (pos-float64(lo))*(sorted[hi]-sorted[lo])
+}
+
+// tQuantiles95 son los cuantiles 0.975 de la t de Student para 1..30 grados
+// de libertad.
+var tQuantiles95 = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// tQuantile95 es el factor del intervalo de confianza del 95% con df grados
+// de libertad; desde 31 se usa la aproximación normal.
+func tQuantile95(df int) float64 {
+	if df >= 1 && df <= len(tQuantiles95) {
+		return tQuantiles95[df-1]
+	}
+	return 1.96
+}
+
+// CSVHeader es el encabezado de los CSV de tiempos. Las primeras cinco
+// columnas son las de siempre; las estadísticas van al final para que los
+// lectores que solo miran n y avg_ms sigan funcionando.
+var CSVHeader = []string{"exercise", "n", "avg_ms", "runs", "note",
+	"min_ms", "median_ms", "p95_ms", "std_ms", "ci95_ms", "warmup", "ops",
+	"alloc_bytes", "allocs", "num_gc", "peak_heap_bytes"}
+
+// Row convierte res en una fila de CSVHeader.
+func (res Result) Row(label string) []string {
+	ms := func(v float64) string {
+		if res.Runs == 0 {
+			return "" // ninguna corrida terminó (timeout): no hay tiempo que graficar
+		}
+		return fmt.Sprintf("%.6f", v)
+	}
+	opsText := strconv.FormatUint(res.Ops, 10)
+	if res.Runs == 0 {
+		opsText = ""
+	}
+	row := []string{
+		label,
+		strconv.Itoa(res.N),
+		ms(res.AvgMs),
+		strconv.Itoa(res.Runs),
+		res.Note,
+		ms(res.MinMs),
+		ms(res.MedianMs),
+		ms(res.P95Ms),
+		ms(res.StdMs),
+		ms(res.CI95Ms),
+		strconv.Itoa(res.Warmup),
+		opsText,
+	}
+	if m := res.Mem; m != nil {
+		return append(row,
+			fmt.Sprintf("%.1f", m.AllocBytes),
+			fmt.Sprintf("%.1f", m.Allocs),
+			fmt.Sprintf("%.3f", m.NumGC),
+			strconv.FormatUint(m.PeakHeapBytes, 10))
 	}
+	// sin -mem las columnas de memoria quedan vacías
+	return append(row, "", "", "", "")
 }
 
 func EnsureDir(path string) error {
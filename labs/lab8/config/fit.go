@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+)
+
+// Model es una función de crecimiento candidata para el ajuste de
+// complejidad. LogF devuelve ln f(n), así los modelos que crecen rápido, como
+// 2^n, no desbordan.
+type Model struct {
+	Name string
+	LogF func(n float64) float64
+}
+
+// Models son los candidatos que prueba FitModels, del más lento al más rápido.
+var Models = []Model{
+	{"1", func(n float64) float64 { return 0 }},
+	{"log n", func(n float64) float64 { return math.Log(math.Log2(n)) }},
+	{"n", func(n float64) float64 { return math.Log(n) }},
+	{"n log n", func(n float64) float64 { return math.Log(n) + math.Log(math.Log2(n)) }},
+	{"n^2", func(n float64) float64 { return 2 * math.Log(n) }},
+	{"n^2 log n", func(n float64) float64 { return 2*math.Log(n) + math.Log(math.Log2(n)) }},
+	{"n^3", func(n float64) float64 { return 3 * math.Log(n) }},
+	{"2^n", func(n float64) float64 { return n * math.Ln2 }},
+}
+
+// FitResult es el ajuste por mínimos cuadrados de un modelo, y ≈ C·f(n).
+//
+// El ajuste se hace sobre ln y para que todos los tamaños pesen lo mismo
+// aunque los tiempos abarquen varios órdenes de magnitud: ln C es la media de
+// ln y − ln f(n), RMSE es la raíz del error cuadrático medio de los residuos
+// en log y R2 se calcula sobre ln y. Slope es la pendiente log-log del modelo
+// en los mismos tamaños, para comparar con la de los datos (FitReport.Slope).
+type FitResult struct {
+	Model Model
+	C     float64
+	RMSE  float64
+	R2    float64
+	Slope float64
+
+	logC float64 // C puede dar underflow con 2^n
+}
+
+// Predict devuelve C·f(n).
+func (f FitResult) Predict(n float64) float64 {
+	return math.Exp(f.logC + f.Model.LogF(n))
+}
+
+// FitReport guarda los ajustes ordenados de un conjunto de datos y su propia
+// pendiente log-log.
+type FitReport struct {
+	Points int         // filas usadas en el ajuste
+	Slope  float64     // pendiente de ln y contra ln n
+	Fits   []FitResult // el mejor ajuste primero
+}
+
+// Best devuelve el modelo con menor error.
+func (r FitReport) Best() FitResult {
+	return r.Fits[0]
+}
+
+// FitModels ajusta cada modelo de Models a rows y los ordena por RMSE. Se
+// saltan las filas con n < minN, n < 2 (ahí log n es 0) o tiempo no positivo;
+// tienen que quedar al menos dos.
+func FitModels(rows []PlotRow, minN float64) (FitReport, error) {
+	var xs, ys []float64 // ln n, ln y
+	var ns []float64
+	for _, r := range rows {
+		if r.N < 2 || r.N < minN || r.AvgMs <= 0 {
+			continue
+		}
+		ns = append(ns, r.N)
+		xs = append(xs, math.Log(r.N))
+		ys = append(ys, math.Log(r.AvgMs))
+	}
+	if len(ns) < 2 {
+		return FitReport{}, fmt.Errorf("need at least 2 rows with n >= 2 and time > 0, have %d", len(ns))
+	}
+
+	yMean := mean(ys)
+	var ssTot float64
+	for _, y := range ys {
+		ssTot += (y - yMean) * (y - yMean)
+	}
+
+	report := FitReport{Points: len(ns), Slope: slope(xs, ys)}
+	for _, m := range Models {
+		lf := make([]float64, len(ns))
+		resid := make([]float64, len(ns))
+		for i, n := range ns {
+			lf[i] = m.LogF(n)
+			resid[i] = ys[i] - lf[i]
+		}
+		logC := mean(resid)
+		var ssRes float64
+		for _, r := range resid {
+			ssRes += (r - logC) * (r - logC)
+		}
+		r2 := 1.0
+		if ssTot > 0 {
+			r2 = 1 - ssRes/ssTot
+		}
+		report.Fits = append(report.Fits, FitResult{
+			Model: m,
+			C:     math.Exp(logC),
+			RMSE:  math.Sqrt(ssRes / float64(len(ns))),
+			R2:    r2,
+			Slope: slope(xs, lf),
+			logC:  logC,
+		})
+	}
+	sort.SliceStable(report.Fits, func(i, j int) bool { return report.Fits[i].RMSE < report.Fits[j].RMSE })
+	return report, nil
+}
+
+// WriteFitReport imprime el ranking como tabla.
+func WriteFitReport(w io.Writer, r FitReport) error {
+	fmt.Fprintf(w, "points=%d  log-log slope=%.3f\n", r.Points, r.Slope)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "rank\tmodel\tC\tR2(log)\tRMSE(log)\tslope")
+	for i, f := range r.Fits {
+		fmt.Fprintf(tw, "%d\t%s\t%.4g\t%.4g\t%.4g\t%.4g\n", i+1, f.Model.Name, f.C, f.R2, f.RMSE, f.Slope)
+	}
+	return tw.Flush()
+}
+
+func mean(v []float64) float64 {
+	var s float64
+	for _, x := range v {
+		s += x
+	}
+	return s / float64(len(v))
+}
+
+// slope es la pendiente por mínimos cuadrados de y contra x.
+func slope(x, y []float64) float64 {
+	mx, my := mean(x), mean(y)
+	var num, den float64
+	for i := range x {
+		num += (x[i] - mx) * (y[i] - my)
+		den += (x[i] - mx) * (x[i] - mx)
+	}
+	if den == 0 {
+		return 0
+	}
+	return num / den
+}
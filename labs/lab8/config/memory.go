@@ -0,0 +1,128 @@
+package config
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"time"
+)
+
+// memCounter acumula lo que asigna cada corrida medida. Las lecturas de
+// runtime.ReadMemStats van fuera del cronómetro porque detienen el mundo.
+type memCounter struct {
+	start       runtime.MemStats
+	runs        int
+	bytes, objs uint64
+	gcs         uint32
+}
+
+func (m *memCounter) before() {
+	runtime.ReadMemStats(&m.start)
+}
+
+func (m *memCounter) after() {
+	var end runtime.MemStats
+	runtime.ReadMemStats(&end)
+	m.runs++
+	m.bytes += end.TotalAlloc - m.start.TotalAlloc
+	m.objs += end.Mallocs - m.start.Mallocs
+	m.gcs += end.NumGC - m.start.NumGC
+}
+
+func (m *memCounter) result(peak uint64) *MemResult {
+	if m.runs == 0 {
+		return &MemResult{PeakHeapBytes: peak}
+	}
+	k := float64(m.runs)
+	return &MemResult{
+		AllocBytes:    float64(m.bytes) / k,
+		Allocs:        float64(m.objs) / k,
+		NumGC:         float64(m.gcs) / k,
+		PeakHeapBytes: peak,
+	}
+}
+
+// peakSampleEvery es cada cuánto peakHeap mira el heap.
+const peakSampleEvery = time.Millisecond
+
+// peakHeap corre r(ctx, n) una vez más, sin cronómetro, mientras una
+// goroutine muestrea HeapAlloc. Devuelve el máximo por encima del heap vivo
+// después de un GC previo (0 si el runner no retiene memoria), el contador de
+// r y su error: si ctx corta la corrida, el máximo no sirve.
+func peakHeap(ctx context.Context, r ContextRunner, n int) (uint64, uint64, error) {
+	runtime.GC()
+	var base runtime.MemStats
+	runtime.ReadMemStats(&base)
+
+	var (
+		mu   sync.Mutex
+		peak = base.HeapAlloc
+		done = make(chan struct{})
+		wg   sync.WaitGroup
+	)
+	sample := func() {
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+		mu.Lock()
+		if ms.HeapAlloc > peak {
+			peak = ms.HeapAlloc
+		}
+		mu.Unlock()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		t := time.NewTicker(peakSampleEvery)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				sample()
+			}
+		}
+	}()
+	out, err := r(ctx, n)
+	sample()
+	close(done)
+	wg.Wait()
+	return peak - base.HeapAlloc, out, err
+}
+
+// startCPUProfile empieza a perfilar la CPU hacia path; la función devuelta
+// lo detiene y cierra el archivo.
+func startCPUProfile(path string) (func(), error) {
+	if err := ensureDir(path); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		pprof.StopCPUProfile()
+		f.Close()
+	}, nil
+}
+
+// writeHeapProfile escribe el perfil de heap (con las asignaciones
+// acumuladas) después de un GC, para que los datos estén al día.
+func writeHeapProfile(path string) error {
+	if err := ensureDir(path); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	runtime.GC()
+	return pprof.WriteHeapProfile(f)
+}
@@ -12,14 +12,30 @@ import (
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
 )
 
 type PlotRow struct {
-	N     float64
-	AvgMs float64
+	Label  string // columna exercise
+	N      float64
+	AvgMs  float64
+	CI95   float64 // 0 si el CSV no trae la columna ci95_ms
+	Ops    uint64  // contador del runner; válido si HasOps
+	HasOps bool
 }
 
+// LoadCSV lee las columnas exercise, n y avg_ms (y ci95_ms y ops si
+// existen) buscándolas por nombre en el encabezado; si no están, usa las
+// posiciones 0, 1 y 2. Las filas pueden tener distinta cantidad de columnas,
+// como cuando se agregan filas nuevas a un CSV con el esquema anterior.
 func LoadCSV(path string) ([]PlotRow, error) {
+	return LoadCSVColumn(path, "avg_ms")
+}
+
+// LoadCSVColumn es LoadCSV con otra columna como valor Y (se guarda en
+// AvgMs), por ejemplo alloc_bytes o peak_heap_bytes. Las filas con esa
+// celda vacía se saltan; ci95_ms solo se lee para avg_ms.
+func LoadCSVColumn(path, yName string) ([]PlotRow, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -27,6 +43,7 @@ func LoadCSV(path string) ([]PlotRow, error) {
 	defer f.Close()
 
 	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
 	records, err := r.ReadAll()
 	if err != nil {
 		return nil, err
@@ -35,23 +52,56 @@ func LoadCSV(path string) ([]PlotRow, error) {
 		return nil, fmt.Errorf("no data rows in %s", path)
 	}
 
-	out := make([]PlotRow, 0, len(records)-1)
-	for i, rec := range records {
-		if i == 0 {
-			continue // header
+	labelCol, nCol, yCol, ciCol, opsCol := 0, 1, -1, -1, -1
+	if yName == "avg_ms" {
+		yCol = 2
+	}
+	for i, name := range records[0] {
+		switch name {
+		case "exercise":
+			labelCol = i
+		case "n":
+			nCol = i
+		case yName:
+			yCol = i
+		case "ci95_ms":
+			ciCol = i
+		case "ops":
+			opsCol = i
 		}
-		if len(rec) < 3 {
+	}
+	if yCol < 0 {
+		return nil, fmt.Errorf("%s has no %s column", path, yName)
+	}
+	if yName != "avg_ms" {
+		ciCol = -1
+	}
+
+	out := make([]PlotRow, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		if len(rec) <= nCol || len(rec) <= yCol {
 			continue
 		}
-		nVal, err := strconv.ParseFloat(rec[1], 64)
+		nVal, err := strconv.ParseFloat(rec[nCol], 64)
 		if err != nil {
 			continue
 		}
-		tVal, err := strconv.ParseFloat(rec[2], 64)
+		tVal, err := strconv.ParseFloat(rec[yCol], 64)
 		if err != nil {
 			continue
 		}
-		out = append(out, PlotRow{N: nVal, AvgMs: tVal})
+		row := PlotRow{N: nVal, AvgMs: tVal}
+		if labelCol < len(rec) {
+			row.Label = rec[labelCol]
+		}
+		if ciCol >= 0 && ciCol < len(rec) {
+			row.CI95, _ = strconv.ParseFloat(rec[ciCol], 64)
+		}
+		if opsCol >= 0 && opsCol < len(rec) {
+			v, err := strconv.ParseUint(rec[opsCol], 10, 64)
+			row.Ops, row.HasOps = v, err == nil
+		}
+		out = append(out, row)
 	}
 	return out, nil
 }
@@ -72,6 +122,95 @@ func PlotCSVWithOpts(inCSV, outPNG, title, xLabel, yLabel string,
 	if err != nil {
 		return err
 	}
+	p, err := newRowsPlot(rows, title, xLabel, yLabel, logX, logY, yMin, drawLine)
+	if err != nil {
+		return err
+	}
+	return savePlot(p, outPNG)
+}
+
+// PlotCSVWithFit dibuja lo mismo que PlotCSVWithOpts y encima la curva C·f(n)
+// del ajuste, en rojo punteado y con leyenda. yLabel es además la columna
+// que se grafica (avg_ms, alloc_bytes, ...).
+func PlotCSVWithFit(inCSV, outPNG, title, xLabel, yLabel string,
+	logX, logY bool, yMin float64, drawLine bool, fit FitResult) error {
+
+	rows, err := LoadCSVColumn(inCSV, yLabel)
+	if err != nil {
+		return err
+	}
+	p, err := newRowsPlot(rows, title, xLabel, yLabel, logX, logY, yMin, drawLine)
+	if err != nil {
+		return err
+	}
+	if err := addFitCurve(p, rows, logX, yMin, fit); err != nil {
+		return err
+	}
+	return savePlot(p, outPNG)
+}
+
+// addFitCurve muestrea la curva del ajuste entre el menor y el mayor n de
+// rows (geométricamente si el eje X es logarítmico).
+func addFitCurve(p *plot.Plot, rows []PlotRow, logX bool, yMin float64, fit FitResult) error {
+	line, err := curveLine(rows, logX, yMin, fit)
+	if err != nil || line == nil {
+		return err
+	}
+	line.Color = color.RGBA{R: 220, A: 255}
+	line.Dashes = []vg.Length{vg.Points(4), vg.Points(3)}
+	p.Add(line)
+	p.Legend.Add(fmt.Sprintf("fit: %.3g·%s", fit.C, fit.Model.Name), line)
+	p.Legend.Top = true
+	p.Legend.Left = true
+	return nil
+}
+
+// curveLine arma la línea C·f(n) entre el menor y el mayor n (≥ 2) de rows,
+// sin los puntos por debajo de yMin; nil si no quedan al menos dos.
+func curveLine(rows []PlotRow, logX bool, yMin float64, fit FitResult) (*plotter.Line, error) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, r := range rows {
+		if r.N >= 2 {
+			lo, hi = math.Min(lo, r.N), math.Max(hi, r.N)
+		}
+	}
+	if lo > hi {
+		return nil, nil
+	}
+	const samples = 200
+	pts := make(plotter.XYs, 0, samples+1)
+	for i := 0; i <= samples; i++ {
+		t := float64(i) / samples
+		x := lo + t*(hi-lo)
+		if logX {
+			x = lo * math.Pow(hi/lo, t)
+		}
+		y := fit.Predict(x)
+		if math.IsInf(y, 0) || math.IsNaN(y) || (yMin > 0 && y < yMin) {
+			continue
+		}
+		pts = append(pts, plotter.XY{X: x, Y: y})
+	}
+	if len(pts) < 2 {
+		return nil, nil
+	}
+	return plotter.NewLine(pts)
+}
+
+// newRowsPlot arma el gráfico de PlotCSVWithOpts sin guardarlo.
+func newRowsPlot(rows []PlotRow, title, xLabel, yLabel string,
+	logX, logY bool, yMin float64, drawLine bool) (*plot.Plot, error) {
+
+	p := newAxesPlot(title, xLabel, yLabel, logX, logY, yMin)
+	style := seriesStyle{color: color.Black, shape: draw.RingGlyph{}, errColor: color.Gray{Y: 100}}
+	if _, err := addRows(p, rows, logX, logY, yMin, drawLine, style); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// newAxesPlot crea un gráfico vacío con títulos y escalas.
+func newAxesPlot(title, xLabel, yLabel string, logX, logY bool, yMin float64) *plot.Plot {
 	p := plot.New()
 	p.Title.Text = title
 	p.X.Label.Text = xLabel
@@ -90,6 +229,20 @@ func PlotCSVWithOpts(inCSV, outPNG, title, xLabel, yLabel string,
 	if yMin > 0 {
 		p.Y.Min = yMin
 	}
+	return p
+}
+
+// seriesStyle es el color y el marcador de una serie.
+type seriesStyle struct {
+	color    color.Color
+	shape    draw.GlyphDrawer
+	errColor color.Color
+}
+
+// addRows agrega una serie (línea opcional, barras de error y puntos) y
+// devuelve sus puntos para la leyenda.
+func addRows(p *plot.Plot, rows []PlotRow, logX, logY bool, yMin float64,
+	drawLine bool, style seriesStyle) (*plotter.Scatter, error) {
 
 	pts := make(plotter.XYs, 0, len(rows))
 	for _, r := range rows {
@@ -111,19 +264,64 @@ func PlotCSVWithOpts(inCSV, outPNG, title, xLabel, yLabel string,
 	if drawLine {
 		line, err := plotter.NewLine(pts)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		line.Color = color.Black
+		line.Color = style.color
 		p.Add(line)
 	}
 
+	if err := addErrorBars(p, rows, pts, style.errColor); err != nil {
+		return nil, err
+	}
+
 	scat, err := plotter.NewScatter(pts)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	scat.Radius = vg.Points(2)
+	scat.Color = style.color
+	scat.Shape = style.shape
 	p.Add(scat)
+	return scat, nil
+}
+
+// errorBars son los puntos ya ajustados a la escala junto con el intervalo
+// de confianza de cada uno.
+type errorBars struct {
+	plotter.XYs
+	low, high []float64
+}
+
+func (e errorBars) YError(i int) (float64, float64) { return e.low[i], e.high[i] }
+
+// addErrorBars dibuja ±CI95 en cada punto si el CSV trae esa columna. En
+// escala logarítmica la barra inferior no baja de la mitad del valor.
+func addErrorBars(p *plot.Plot, rows []PlotRow, pts plotter.XYs, c color.Color) error {
+	bars := errorBars{XYs: pts, low: make([]float64, len(pts)), high: make([]float64, len(pts))}
+	found := false
+	for i, r := range rows {
+		if r.CI95 <= 0 {
+			continue
+		}
+		found = true
+		bars.low[i], bars.high[i] = r.CI95, r.CI95
+		if _, isLog := p.Y.Scale.(plot.LogScale); isLog && bars.low[i] >= pts[i].Y {
+			bars.low[i] = pts[i].Y / 2
+		}
+	}
+	if !found {
+		return nil
+	}
+	eb, err := plotter.NewYErrorBars(bars)
+	if err != nil {
+		return err
+	}
+	eb.Color = c
+	p.Add(eb)
+	return nil
+}
 
+func savePlot(p *plot.Plot, outPNG string) error {
 	if err := ensureDir(outPNG); err != nil {
 		return err
 	}
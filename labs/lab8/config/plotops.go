@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"image/color"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// OpsCheck compara el contador de un runner con la fórmula cerrada en un n.
+type OpsCheck struct {
+	N        int
+	Counted  uint64
+	Expected uint64
+}
+
+// Diff es Counted − Expected.
+func (c OpsCheck) Diff() int64 {
+	return int64(c.Counted) - int64(c.Expected)
+}
+
+// CheckOps evalúa f en cada fila con columna ops.
+func CheckOps(rows []PlotRow, f Formula) ([]OpsCheck, error) {
+	var out []OpsCheck
+	for _, r := range rows {
+		if !r.HasOps {
+			continue
+		}
+		n := int(r.N)
+		out = append(out, OpsCheck{N: n, Counted: r.Ops, Expected: f(n)})
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no rows with an ops column")
+	}
+	return out, nil
+}
+
+// PlotOps dibuja la fórmula cerrada como línea y las operaciones contadas
+// como puntos; los n donde no coinciden quedan marcados en rojo.
+func PlotOps(checks []OpsCheck, outPNG, title string, logX, logY bool) error {
+	p := plot.New()
+	p.Title.Text = title
+	p.X.Label.Text = "n"
+	p.Y.Label.Text = "operations"
+	if logX {
+		p.X.Scale = plot.LogScale{}
+		p.X.Tick.Marker = plot.LogTicks{}
+	}
+	if logY {
+		p.Y.Scale = plot.LogScale{}
+		p.Y.Tick.Marker = plot.LogTicks{}
+	}
+
+	var expected, counted, wrong plotter.XYs
+	for _, c := range checks {
+		// en escala log no hay lugar para n = 0 ni para 0 operaciones
+		if logX && c.N <= 0 {
+			continue
+		}
+		if !logY || c.Expected > 0 {
+			expected = append(expected, plotter.XY{X: float64(c.N), Y: float64(c.Expected)})
+		}
+		if logY && c.Counted == 0 {
+			continue
+		}
+		pt := plotter.XY{X: float64(c.N), Y: float64(c.Counted)}
+		if c.Counted == c.Expected {
+			counted = append(counted, pt)
+		} else {
+			wrong = append(wrong, pt)
+		}
+	}
+
+	if len(expected) > 0 {
+		line, err := plotter.NewLine(expected)
+		if err != nil {
+			return err
+		}
+		line.Color = color.RGBA{B: 200, A: 255}
+		p.Add(line)
+		p.Legend.Add("closed form", line)
+	}
+	if len(counted) > 0 {
+		scat, err := plotter.NewScatter(counted)
+		if err != nil {
+			return err
+		}
+		scat.Radius = vg.Points(3)
+		p.Add(scat)
+		p.Legend.Add("counted", scat)
+	}
+	if len(wrong) > 0 {
+		scat, err := plotter.NewScatter(wrong)
+		if err != nil {
+			return err
+		}
+		scat.Color = color.RGBA{R: 220, A: 255}
+		scat.Shape = draw.CrossGlyph{}
+		scat.Radius = vg.Points(5)
+		p.Add(scat)
+		p.Legend.Add("counted ≠ closed form", scat)
+	}
+	p.Legend.Top = true
+	p.Legend.Left = true
+	return savePlot(p, outPNG)
+}
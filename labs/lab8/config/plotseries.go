@@ -0,0 +1,197 @@
+package config
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// Series es una curva con nombre dentro de un gráfico comparativo.
+type Series struct {
+	Label string
+	Rows  []PlotRow
+}
+
+// GroupByLabel separa rows en una serie por valor de la columna exercise,
+// en el orden en que aparece cada valor.
+func GroupByLabel(rows []PlotRow) []Series {
+	var out []Series
+	index := make(map[string]int)
+	for _, r := range rows {
+		i, ok := index[r.Label]
+		if !ok {
+			i = len(out)
+			index[r.Label] = i
+			out = append(out, Series{Label: r.Label})
+		}
+		out[i].Rows = append(out[i].Rows, r)
+	}
+	return out
+}
+
+// LoadSeries lee la columna yName de varios CSV. Sin group cada archivo es
+// una serie, con el valor de exercise de su primera fila como nombre; con
+// group cada archivo se separa con GroupByLabel. Si dos series quedan con el
+// mismo nombre se les antepone el nombre del archivo.
+func LoadSeries(paths []string, group bool, yName string) ([]Series, error) {
+	var out []Series
+	var files []string
+	for _, path := range paths {
+		rows, err := LoadCSVColumn(path, yName)
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		if group {
+			for _, s := range GroupByLabel(rows) {
+				out = append(out, s)
+				files = append(files, path)
+			}
+			continue
+		}
+		label := rows[0].Label
+		if label == "" {
+			label = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		out = append(out, Series{Label: label, Rows: rows})
+		files = append(files, path)
+	}
+	seen := make(map[string]int)
+	for _, s := range out {
+		seen[s.Label]++
+	}
+	for i := range out {
+		if seen[out[i].Label] > 1 {
+			out[i].Label = filepath.Base(files[i]) + ": " + out[i].Label
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no data rows in %s", strings.Join(paths, ", "))
+	}
+	return out, nil
+}
+
+// PlotOptions son las opciones de PlotSeries. Refs son nombres de Models
+// ("n", "n^2", ...) que se dibujan como curvas de referencia, escaladas para
+// pasar por el último punto (el de mayor n) de la serie que termina más alto.
+type PlotOptions struct {
+	Title, XLabel, YLabel string
+	LogX, LogY            bool
+	YMin                  float64
+	DrawLine              bool
+	Refs                  []string
+}
+
+// seriesColors y seriesShapes se recorren en orden, una combinación por serie.
+var seriesColors = []color.Color{
+	color.RGBA{R: 31, G: 119, B: 180, A: 255},
+	color.RGBA{R: 255, G: 127, B: 14, A: 255},
+	color.RGBA{R: 44, G: 160, B: 44, A: 255},
+	color.RGBA{R: 214, G: 39, B: 40, A: 255},
+	color.RGBA{R: 148, G: 103, B: 189, A: 255},
+	color.RGBA{R: 140, G: 86, B: 75, A: 255},
+	color.RGBA{R: 227, G: 119, B: 194, A: 255},
+	color.RGBA{R: 23, G: 190, B: 207, A: 255},
+}
+
+var seriesShapes = []draw.GlyphDrawer{
+	draw.CircleGlyph{}, draw.SquareGlyph{}, draw.TriangleGlyph{}, draw.CrossGlyph{},
+	draw.PlusGlyph{}, draw.RingGlyph{}, draw.BoxGlyph{}, draw.PyramidGlyph{},
+}
+
+// PlotSeries dibuja todas las series en un gráfico con leyenda. El formato
+// sale de la extensión de out (.png, .svg, .pdf, ...).
+func PlotSeries(series []Series, out string, opts PlotOptions) error {
+	p := newAxesPlot(opts.Title, opts.XLabel, opts.YLabel, opts.LogX, opts.LogY, opts.YMin)
+	var all []PlotRow
+	var anchor PlotRow
+	hasAnchor := false
+	for i, s := range series {
+		c := seriesColors[i%len(seriesColors)]
+		style := seriesStyle{color: c, shape: seriesShapes[i%len(seriesShapes)], errColor: c}
+		scat, err := addRows(p, s.Rows, opts.LogX, opts.LogY, opts.YMin, opts.DrawLine, style)
+		if err != nil {
+			return err
+		}
+		p.Legend.Add(s.Label, scat)
+		all = append(all, s.Rows...)
+		if last, ok := largestN(s.Rows); ok && (!hasAnchor || last.AvgMs > anchor.AvgMs) {
+			anchor, hasAnchor = last, true
+		}
+	}
+
+	if len(opts.Refs) > 0 {
+		if !hasAnchor {
+			return fmt.Errorf("no point with n >= 2 and y > 0 to scale the reference curves")
+		}
+		for i, name := range opts.Refs {
+			m, ok := ModelByName(name)
+			if !ok {
+				return fmt.Errorf("unknown reference curve %q", name)
+			}
+			line, err := curveLine(all, opts.LogX, opts.YMin, RefCurve(m, anchor.N, anchor.AvgMs))
+			if err != nil {
+				return err
+			}
+			if line == nil {
+				continue
+			}
+			line.Color = color.Gray{Y: uint8(60 + 40*(i%4))}
+			line.Dashes = []vg.Length{vg.Points(2 + float64(2*i)), vg.Points(3)}
+			p.Add(line)
+			p.Legend.Add("ref "+m.Name, line)
+		}
+	}
+	p.Legend.Top = true
+	p.Legend.Left = true
+	return savePlot(p, out)
+}
+
+// largestN devuelve la fila de mayor n con n ≥ 2 y tiempo positivo.
+func largestN(rows []PlotRow) (PlotRow, bool) {
+	var best PlotRow
+	found := false
+	for _, r := range rows {
+		if r.N >= 2 && r.AvgMs > 0 && (!found || r.N > best.N) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+// ModelByName busca un modelo de Models por nombre ("n^2", "n log n", ...).
+func ModelByName(name string) (Model, bool) {
+	for _, m := range Models {
+		if m.Name == strings.TrimSpace(name) {
+			return m, true
+		}
+	}
+	return Model{}, false
+}
+
+// RefCurve es la curva C·f(n) del modelo m que pasa por (n, y).
+func RefCurve(m Model, n, y float64) FitResult {
+	logC := math.Log(y) - m.LogF(n)
+	return FitResult{Model: m, C: math.Exp(logC), logC: logC}
+}
+
+// OutputPath cambia la extensión de path por format ("png" o "svg"); con
+// format vacío devuelve path tal cual y el formato sale de su extensión.
+func OutputPath(path, format string) (string, error) {
+	if format == "" {
+		return path, nil
+	}
+	switch format {
+	case "png", "svg":
+	default:
+		return "", fmt.Errorf("output format must be png or svg, not %q", format)
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path)) + "." + format, nil
+}
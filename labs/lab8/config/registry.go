@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Exercise es un algoritmo registrado: la CLI lo busca por Name.
+type Exercise struct {
+	Name        string  // p. ej. "ex3", el que usa -exercise
+	Label       string  // valor de la columna exercise del CSV, p. ej. "ex03"
+	Description string  // una línea para -list
+	Complexity  string  // complejidad temporal esperada, p. ej. "O(n^2)"
+	Runner      Runner  // lo que mide el modo run
+	Ops         Formula // valor exacto del contador de Runner; nil si no se conoce
+
+	// RunnerCtx, si no es nil, es una versión interrumpible de Runner que se
+	// usa cuando el modo run tiene -timeout.
+	RunnerCtx ContextRunner
+
+	// Setup, si no es nil, arma la entrada para n fuera del cronómetro y
+	// devuelve el Runner a medir sobre ella (su argumento n se ignora).
+	// Runner igual tiene que hacer todo el trabajo, preparación incluida,
+	// para quien solo tiene un tamaño.
+	Setup func(n int) Runner
+
+	// SetupCtx, si no es nil, es Setup con un runner interrumpible, como
+	// RunnerCtx lo es de Runner.
+	SetupCtx func(n int) ContextRunner
+}
+
+// Measured devuelve lo que TimeNContext corre con tamaño n. Con interrupt,
+// la corrida tiene que terminar cuando termina su contexto: SetupCtx o
+// RunnerCtx si hay, si no el runner envuelto con AbandonOnCancel.
+func (e Exercise) Measured(n int, interrupt bool) ContextRunner {
+	r := e.Runner
+	switch {
+	case e.SetupCtx != nil:
+		return e.SetupCtx(n)
+	case e.Setup != nil:
+		r = e.Setup(n)
+	case e.RunnerCtx != nil:
+		return e.RunnerCtx
+	}
+	if interrupt {
+		return r.AbandonOnCancel()
+	}
+	return r.WithContext()
+}
+
+// DefaultCSV es donde el modo run escribe el ejercicio si -out está vacío.
+func (e Exercise) DefaultCSV() string {
+	return "results/" + e.Label + ".csv"
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]Exercise)
+)
+
+// Register agrega e al registro; los paquetes de ejercicios lo llaman desde
+// init. Si Label está vacío se usa Name. Entra en pánico si el nombre está
+// vacío o repetido o si falta Runner, porque es un error de programación.
+func Register(e Exercise) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if e.Name == "" || e.Runner == nil {
+		panic("config: Register needs a Name and a Runner")
+	}
+	if _, dup := registry[e.Name]; dup {
+		panic("config: Register called twice for " + e.Name)
+	}
+	if e.Label == "" {
+		e.Label = e.Name
+	}
+	registry[e.Name] = e
+}
+
+// Lookup devuelve el ejercicio registrado con ese nombre. También acepta
+// solo el número, así "3" encuentra "ex3" como el viejo -exercise=3.
+func Lookup(name string) (Exercise, bool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	name = strings.TrimSpace(name)
+	if e, ok := registry[name]; ok {
+		return e, true
+	}
+	e, ok := registry["ex"+name]
+	return e, ok
+}
+
+// Exercises devuelve todos los ejercicios registrados, ordenados por nombre.
+func Exercises() []Exercise {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	out := make([]Exercise, 0, len(registry))
+	for _, e := range registry {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// UnknownExercise es el error para un nombre que no está en el registro.
+func UnknownExercise(name string) error {
+	var names []string
+	for _, e := range Exercises() {
+		names = append(names, e.Name)
+	}
+	return fmt.Errorf("unknown exercise %q (registered: %s)", name, strings.Join(names, ", "))
+}
@@ -1,13 +1,101 @@
 package config
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
 // Runner is the function signature for an exercise implementation.
 // It must return a counter to avoid dead-code elimination by the compiler.
 type Runner func(n int) uint64
 
-// Result is the timing summary for a single input size.
+// ContextRunner is a Runner that can be interrupted: it should check ctx
+// every so often and return ctx.Err() once it is done.
+type ContextRunner func(ctx context.Context, n int) (uint64, error)
+
+// WithContext adapts r without making it interruptible: ctx is only checked
+// between runs, so a run that has started always finishes.
+func (r Runner) WithContext() ContextRunner {
+	return func(_ context.Context, n int) (uint64, error) { return r(n), nil }
+}
+
+// ErrAbandoned is wrapped, together with ctx.Err(), by the error of a run
+// that AbandonOnCancel left running.
+var ErrAbandoned = errors.New("abandoned run still using a CPU")
+
+// AbandonOnCancel adapts r by running it in its own goroutine and returning
+// as soon as ctx is done. The abandoned run keeps using a CPU until it ends,
+// which slows down whatever is measured next, so it is only a fallback for
+// runners that have no ContextRunner; its error wraps ErrAbandoned so the
+// row's note says so.
+func (r Runner) AbandonOnCancel() ContextRunner {
+	return func(ctx context.Context, n int) (uint64, error) {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		done := make(chan uint64, 1)
+		go func() { done <- r(n) }()
+		select {
+		case v := <-done:
+			return v, nil
+		case <-ctx.Done():
+			return 0, fmt.Errorf("%w (%w)", ctx.Err(), ErrAbandoned)
+		}
+	}
+}
+
+// Result is the timing summary for a single input size. Runs counts the
+// measured runs only, not the warm-up ones.
 type Result struct {
 	N     int
 	AvgMs float64
 	Runs  int
 	Note  string
+
+	MinMs    float64
+	MedianMs float64
+	P95Ms    float64
+	StdMs    float64 // sample standard deviation
+	CI95Ms   float64 // half-width of the 95% confidence interval of AvgMs
+	Warmup   int
+	Ops      uint64 // counter returned by the runner (the same on every run)
+
+	Mem *MemResult // nil unless TimeOptions.Memory is set
+}
+
+// MemResult is the memory use of a runner for one input size. The per-run
+// values are averages over the measured runs.
+type MemResult struct {
+	AllocBytes    float64 // bytes allocated per run
+	Allocs        float64 // heap objects allocated per run
+	NumGC         float64 // garbage collections per run
+	PeakHeapBytes uint64  // highest live heap above the baseline, from an extra run
+}
+
+// Formula is the exact closed-form operation count of an exercise, to check
+// against the counter its Runner returns.
+type Formula func(n int) uint64
+
+// TimeOptions controls how TimeNWithOpts measures a runner.
+type TimeOptions struct {
+	Warmup    int           // unmeasured runs before measuring
+	MinRuns   int           // measured runs always done
+	MaxRuns   int           // upper bound for adaptive runs
+	RelErr    float64       // stop when CI95/mean falls below this (0: run MinRuns only)
+	MaxBudget time.Duration // stop adding runs once the measured time exceeds this
+
+	Memory        bool   // also measure allocations, GC and peak heap
+	ProfilePrefix string // if set, write <prefix>.cpu.pprof and <prefix>.heap.pprof
+}
+
+// DefaultTimeOptions are the options used by TimeN, with MinRuns set to its
+// runs argument.
+var DefaultTimeOptions = TimeOptions{
+	Warmup:    1,
+	MinRuns:   3,
+	MaxRuns:   50,
+	RelErr:    0.05,
+	MaxBudget: 5 * time.Second,
 }
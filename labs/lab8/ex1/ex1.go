@@ -1,8 +1,36 @@
 package ex1
 
+import (
+	"context"
+	"math/bits"
+
+	"lab8/config"
+)
+
+func init() {
+	config.Register(config.Exercise{
+		Name:        "ex1",
+		Label:       "ex01",
+		Description: "i: n/2..n, j: 1..n/2, k doubling up to n",
+		Complexity:  "O(n^2 log n)",
+		Runner:      Ex1,
+		Ops:         Ex1Ops,
+		RunnerCtx:   Ex1Ctx,
+	})
+}
+
 func Ex1(n int) uint64 {
+	counter, _ := Ex1Ctx(context.Background(), n)
+	return counter
+}
+
+// Ex1Ctx is Ex1 checking ctx once per iteration of the outer loop.
+func Ex1Ctx(ctx context.Context, n int) (uint64, error) {
 	var counter uint64
 	for i := n / 2; i <= n; i++ {
+		if err := ctx.Err(); err != nil {
+			return counter, err
+		}
 		for j := 1; j+n/2 <= n; j++ {
 			for k := 1; k <= n; k = k * 2 {
 				// Work: simple increment to keep the loop non-trivial
@@ -10,5 +38,16 @@ func Ex1(n int) uint64 {
 			}
 		}
 	}
-	return counter
+	return counter, nil
+}
+
+// Ex1Ops is the exact value returned by Ex1: the i loop runs n-⌊n/2⌋+1
+// times, the j loop n-⌊n/2⌋ times and the k loop ⌊log₂ n⌋+1 times. For even
+// n this is (n/2+1)·(n/2)·(⌊log₂ n⌋+1).
+func Ex1Ops(n int) uint64 {
+	if n < 1 {
+		return 0
+	}
+	half := uint64(n - n/2)
+	return (half + 1) * half * uint64(bits.Len(uint(n)))
 }
@@ -1,17 +1,54 @@
 package ex2
 
+import (
+	"context"
+
+	"lab8/config"
+)
+
+func init() {
+	config.Register(config.Exercise{
+		Name:        "ex2",
+		Label:       "ex02",
+		Description: "double loop with an immediate break in the inner one",
+		Complexity:  "O(n)",
+		Runner:      Ex2,
+		Ops:         Ex2Ops,
+		RunnerCtx:   Ex2Ctx,
+	})
+}
+
 // Ex2 implements Exercise 2 with an early break in the inner loop.
 // Time complexity: O(n) for n > 1; O(1) when n <= 1.
 func Ex2(n int) uint64 {
+	counter, _ := Ex2Ctx(context.Background(), n)
+	return counter
+}
+
+// Ex2Ctx is Ex2 checking ctx every 1024 iterations of the outer loop.
+func Ex2Ctx(ctx context.Context, n int) (uint64, error) {
 	if n <= 1 {
-		return 0
+		return 0, nil
 	}
 	var counter uint64
 	for i := 1; i <= n; i++ {
+		if i&1023 == 0 {
+			if err := ctx.Err(); err != nil {
+				return counter, err
+			}
+		}
 		for j := 1; j <= n; j++ {
 			counter++ // simulate "printf" + break
 			break
 		}
 	}
-	return counter
+	return counter, nil
+}
+
+// Ex2Ops is the exact value returned by Ex2: n for n > 1, 0 otherwise.
+func Ex2Ops(n int) uint64 {
+	if n <= 1 {
+		return 0
+	}
+	return uint64(n)
 }
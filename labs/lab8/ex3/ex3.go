@@ -1,13 +1,49 @@
 package ex3
 
+import (
+	"context"
+
+	"lab8/config"
+)
+
+func init() {
+	config.Register(config.Exercise{
+		Name:        "ex3",
+		Label:       "ex03",
+		Description: "i: 1..n/3, j: 1..n step 4",
+		Complexity:  "O(n^2)",
+		Runner:      Ex3,
+		Ops:         Ex3Ops,
+		RunnerCtx:   Ex3Ctx,
+	})
+}
+
 // Ex3 implements Exercise 3: i in [1..n/3], j in [1..n] step 4.
 // Time complexity: O(n^2), space O(1).
 func Ex3(n int) uint64 {
+	counter, _ := Ex3Ctx(context.Background(), n)
+	return counter
+}
+
+// Ex3Ctx is Ex3 checking ctx once per iteration of the outer loop.
+func Ex3Ctx(ctx context.Context, n int) (uint64, error) {
 	var counter uint64
 	for i := 1; i <= n/3; i++ {
+		if err := ctx.Err(); err != nil {
+			return counter, err
+		}
 		for j := 1; j <= n; j += 4 {
 			counter++
 		}
 	}
-	return counter
+	return counter, nil
+}
+
+// Ex3Ops is the exact value returned by Ex3: ⌊n/3⌋ iterations of i times
+// ⌈n/4⌉ iterations of j.
+func Ex3Ops(n int) uint64 {
+	if n < 1 {
+		return 0
+	}
+	return uint64(n/3) * uint64((n+3)/4)
 }
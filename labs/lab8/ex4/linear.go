@@ -18,6 +18,15 @@ func AvgComparisonsMixed(n int, p float64) float64 {
 	return p*float64(n+1)/2 + (1-p)*float64(n)
 }
 
+// checkProbability rejects a p outside [0, 1] (or NaN): the mixed formulas
+// would give meaningless values, such as a negative variance.
+func checkProbability(p float64) error {
+	if !(p >= 0 && p <= 1) {
+		return fmt.Errorf("p must be between 0 and 1, got %v", p)
+	}
+	return nil
+}
+
 func ensureDir(dir string) error {
 	if dir == "" || dir == "." {
 		return nil
@@ -27,6 +36,9 @@ func ensureDir(dir string) error {
 
 // GenerateCSVs writes four CSVs into outDir: best, avg_success, avg_mixed_pXX, worst.
 func GenerateCSVs(ns []int, p float64, outDir string) error {
+	if err := checkProbability(p); err != nil {
+		return err
+	}
 	if err := ensureDir(outDir); err != nil {
 		return err
 	}
@@ -0,0 +1,151 @@
+package ex4
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"lab8/config"
+)
+
+// VarComparisonsMixed is the variance of the comparisons made by
+// LinearSearch when x is present with probability p (uniform over the n
+// positions) and absent otherwise.
+func VarComparisonsMixed(n int, p float64) float64 {
+	fn := float64(n)
+	mean := AvgComparisonsMixed(n, p)
+	second := p*(fn+1)*(2*fn+1)/6 + (1-p)*fn*fn // E[C²]
+	return second - mean*mean
+}
+
+// Empirical is the outcome of a Monte Carlo run of LinearSearch for one n.
+type Empirical struct {
+	N        int
+	Trials   int
+	Mean     float64 // measured comparisons
+	Variance float64 // sample variance of the measured comparisons
+	Formula  float64 // AvgComparisonsMixed(n, p)
+	FormVar  float64 // VarComparisonsMixed(n, p)
+}
+
+// CI95 is the half-width of the 95% confidence interval of Mean.
+func (e Empirical) CI95() float64 {
+	if e.Trials < 2 {
+		return 0
+	}
+	return 1.96 * math.Sqrt(e.Variance/float64(e.Trials))
+}
+
+// SimulateLinear runs LinearSearch trials times on arrays of n distinct
+// values in random order. With probability p the target is one of the
+// values, chosen uniformly; otherwise it is a value not in the array. p
+// must be in [0, 1].
+func SimulateLinear(rng *rand.Rand, n, trials int, p float64) (Empirical, error) {
+	if n < 1 || trials < 1 {
+		return Empirical{}, fmt.Errorf("need n >= 1 and trials >= 1, got n=%d trials=%d", n, trials)
+	}
+	if err := checkProbability(p); err != nil {
+		return Empirical{}, err
+	}
+	var sum, sumSq float64
+	for t := 0; t < trials; t++ {
+		a := rng.Perm(n) // values 0..n-1, so n is never found
+		x, want := n, -1
+		if rng.Float64() < p {
+			want = rng.Intn(n)
+			x = a[want]
+		}
+		idx, c := LinearSearch(a, x)
+		if idx != want {
+			return Empirical{}, fmt.Errorf("LinearSearch returned %d, want %d", idx, want)
+		}
+		fc := float64(c)
+		sum += fc
+		sumSq += fc * fc
+	}
+	k := float64(trials)
+	mean := sum / k
+	variance := 0.0
+	if trials > 1 {
+		variance = (sumSq - k*mean*mean) / (k - 1)
+	}
+	return Empirical{
+		N:        n,
+		Trials:   trials,
+		Mean:     mean,
+		Variance: math.Max(variance, 0),
+		Formula:  AvgComparisonsMixed(n, p),
+		FormVar:  VarComparisonsMixed(n, p),
+	}, nil
+}
+
+// EmpiricalCSVName is the file GenerateEmpiricalCSV writes for p.
+func EmpiricalCSVName(p float64) string {
+	return "ex04_empirical_p" + strconv.FormatFloat(p, 'f', 2, 64) + ".csv"
+}
+
+// GenerateEmpiricalCSV simulates every n with a RNG seeded with seed and
+// writes outDir/EmpiricalCSVName(p). As in GenerateCSVs, avg_ms holds
+// comparisons (the measured mean) and runs holds the number of trials;
+// formula and formula_variance are the analytic values.
+func GenerateEmpiricalCSV(ns []int, p float64, trials int, seed int64, outDir string) (string, error) {
+	if err := checkProbability(p); err != nil {
+		return "", err
+	}
+	if err := ensureDir(outDir); err != nil {
+		return "", err
+	}
+	path := filepath.Join(outDir, EmpiricalCSVName(p))
+	if err := os.RemoveAll(path); err != nil {
+		return "", err
+	}
+	header := []string{"exercise", "n", "avg_ms", "runs", "note",
+		"ci95_ms", "variance", "formula", "formula_variance"}
+	rng := rand.New(rand.NewSource(seed))
+	for _, n := range ns {
+		e, err := SimulateLinear(rng, n, trials, p)
+		if err != nil {
+			return "", err
+		}
+		row := []string{
+			"ex04-empirical",
+			strconv.Itoa(n),
+			fmt.Sprintf("%.6f", e.Mean),
+			strconv.Itoa(e.Trials),
+			fmt.Sprintf("seed=%d p=%.2f", seed, p),
+			fmt.Sprintf("%.6f", e.CI95()),
+			fmt.Sprintf("%.6f", e.Variance),
+			fmt.Sprintf("%.6f", e.Formula),
+			fmt.Sprintf("%.6f", e.FormVar),
+		}
+		if err := config.AppendCSV(path, header, [][]string{row}); err != nil {
+			return "", err
+		}
+	}
+	return path, nil
+}
+
+// PlotEmpirical overlays the measured mean (with its 95% CI) and the
+// formula from a CSV written by GenerateEmpiricalCSV.
+func PlotEmpirical(csvPath, outPlot, title string, logX, logY bool) error {
+	measured, err := config.LoadCSV(csvPath)
+	if err != nil {
+		return err
+	}
+	formula, err := config.LoadCSVColumn(csvPath, "formula")
+	if err != nil {
+		return err
+	}
+	series := []config.Series{
+		{Label: "formula", Rows: formula},
+		{Label: "measured (Monte Carlo)", Rows: measured},
+	}
+	opts := config.PlotOptions{
+		Title: title, XLabel: "n", YLabel: "comparisons",
+		LogX: logX, LogY: logY, DrawLine: true,
+	}
+	return config.PlotSeries(series, outPlot, opts)
+}
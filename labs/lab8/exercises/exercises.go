@@ -0,0 +1,11 @@
+// Package exercises links every exercise package into the binary. Each one
+// registers itself with config.Register from init, so adding an algorithm
+// means adding its import here, not editing main.go.
+package exercises
+
+import (
+	_ "lab8/ex1"
+	_ "lab8/ex2"
+	_ "lab8/ex3"
+	_ "lab8/sorting"
+)
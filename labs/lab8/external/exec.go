@@ -0,0 +1,163 @@
+// Package external measures programs outside lab8 with the same CSV schema
+// as the run mode: commands run once per repetition (Command) and the
+// output of go test -bench (ParseGoBench).
+package external
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"lab8/config"
+	"lab8/inputs"
+)
+
+// Command is a shell command template, run with sh -c. {n} is replaced with
+// the size and {input} with the path of the generated input file; without
+// {input} the file, if any, is the command's standard input.
+type Command struct {
+	Template string
+	Input    string // see WriteInput; "" for none
+	Seed     int64  // seed of the input generators
+}
+
+// WriteInput writes to path the input of size n described by spec:
+//
+//	<distribution>   a name from inputs.Distributions: a line with n, then
+//	                 the n values separated by spaces
+//	words:<a,b,...>  n words cycling through the list, separated by spaces
+//	                 (e.g. sentences of growing length for a parser)
+//
+// The distributions are generated with a RNG seeded with seed. The file
+// ends with a newline.
+func WriteInput(path, spec string, n int, seed int64) error {
+	var buf bytes.Buffer
+	if list, ok := strings.CutPrefix(spec, "words:"); ok {
+		words := strings.Split(list, ",")
+		if list == "" {
+			return fmt.Errorf("input %q has no words", spec)
+		}
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(words[i%len(words)])
+		}
+		buf.WriteByte('\n')
+	} else {
+		d, err := inputs.Lookup(spec)
+		if err != nil {
+			return err
+		}
+		a := d.Gen(rand.New(rand.NewSource(seed)), n)
+		buf.WriteString(strconv.Itoa(n))
+		buf.WriteByte('\n')
+		for i, v := range a {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(strconv.Itoa(v))
+		}
+		buf.WriteByte('\n')
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// Prepare writes the input for n, if the command has one, and returns the
+// runner to time plus a function that deletes the input. Each call of the
+// runner starts the command and waits for it; the context kills it. The
+// runner's count is always 0: a command has no operation counter.
+func (c Command) Prepare(n int) (config.ContextRunner, func(), error) {
+	if strings.TrimSpace(c.Template) == "" {
+		return nil, nil, errors.New("empty command")
+	}
+	line := strings.ReplaceAll(c.Template, "{n}", strconv.Itoa(n))
+	done := func() {}
+	var stdin string
+	if c.Input != "" {
+		dir, err := os.MkdirTemp("", "lab8-exec-")
+		if err != nil {
+			return nil, nil, err
+		}
+		done = func() { os.RemoveAll(dir) }
+		path := filepath.Join(dir, fmt.Sprintf("input_n%d.txt", n))
+		if err := WriteInput(path, c.Input, n, c.Seed); err != nil {
+			done()
+			return nil, nil, err
+		}
+		if strings.Contains(line, "{input}") {
+			line = strings.ReplaceAll(line, "{input}", path)
+		} else {
+			stdin = path
+		}
+	}
+
+	run := func(ctx context.Context, _ int) (uint64, error) {
+		// the line may be any shell code (cd, VAR=1 cmd, pipes), so canceling
+		// kills the shell's whole process group, not just the shell
+		cmd := exec.CommandContext(ctx, "sh", "-c", line)
+		killGroupOnCancel(cmd)
+		cmd.WaitDelay = time.Second // do not wait forever for grandchildren holding the pipes
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if stdin != "" {
+			f, err := os.Open(stdin)
+			if err != nil {
+				return 0, err
+			}
+			defer f.Close()
+			cmd.Stdin = f
+		}
+		if err := cmd.Run(); err != nil {
+			if ctx.Err() != nil {
+				return 0, ctx.Err()
+			}
+			return 0, fmt.Errorf("%s: %v%s", line, err, lastLine(stderr.Bytes()))
+		}
+		return 0, nil
+	}
+	return run, done, nil
+}
+
+// lastLine is ": " plus the last non-empty line of out, or "".
+func lastLine(out []byte) string {
+	var last string
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		if t := strings.TrimSpace(sc.Text()); t != "" {
+			last = t
+		}
+	}
+	if last == "" {
+		return ""
+	}
+	return ": " + last
+}
+
+// Row is res.Row(label) with the columns the harness cannot know about an
+// external program emptied: ops, and num_gc and peak_heap_bytes when only
+// the allocation figures are known (go test -benchmem).
+func Row(res config.Result, label string) []string {
+	row := res.Row(label)
+	empty := []string{"ops"}
+	if res.Mem != nil {
+		empty = append(empty, "num_gc", "peak_heap_bytes")
+	}
+	for i, name := range config.CSVHeader {
+		for _, c := range empty {
+			if name == c {
+				row[i] = ""
+			}
+		}
+	}
+	return row
+}
@@ -0,0 +1,128 @@
+package external
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"lab8/config"
+)
+
+// BenchResult is one benchmark and size from go test -bench output; the
+// lines repeated by -count are folded into its statistics.
+type BenchResult struct {
+	Label  string // benchmark name without the size and the -GOMAXPROCS suffix
+	Result config.Result
+}
+
+var (
+	// BenchmarkSort/n=1000-8   12345   95234 ns/op   8192 B/op   1 allocs/op
+	benchLine = regexp.MustCompile(`^(Benchmark\S+)\s+(\d+)\s+([0-9.]+) ns/op(.*)$`)
+	procs     = regexp.MustCompile(`-\d+$`)
+	// the last sub-benchmark element is the size: 1000 or name=1000
+	benchSize = regexp.MustCompile(`^(?:[A-Za-z_]\w*=)?(\d+)$`)
+	benchMem  = regexp.MustCompile(`([0-9.]+) (B|allocs)/op`)
+)
+
+// splitBenchName separates a benchmark name into label and size, e.g.
+// "BenchmarkSort/n=1000-8" into "BenchmarkSort" and 1000. The size must be
+// the last sub-benchmark element, written 1000 or name=1000 (b.Run("n=1000",
+// ...)); digits in the benchmark's own name are not a size, so
+// BenchmarkSHA256 has none. The -8 added by go test when GOMAXPROCS > 1 is
+// always dropped first.
+func splitBenchName(name string) (string, int, bool) {
+	name = procs.ReplaceAllString(name, "")
+	i := strings.LastIndexByte(name, '/')
+	if i < 0 {
+		return "", 0, false
+	}
+	m := benchSize.FindStringSubmatch(name[i+1:])
+	if m == nil {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return "", 0, false
+	}
+	return name[:i], n, true
+}
+
+// ParseGoBench reads the output of go test -bench (with or without
+// -benchmem and -count) and returns one BenchResult per benchmark and size,
+// sorted by label and n. avg_ms is ns/op in milliseconds and runs is the
+// number of lines; B/op and allocs/op fill Mem. Lines that are not results
+// are ignored; result lines whose name has no size (see splitBenchName) are
+// skipped, and skipped counts them.
+func ParseGoBench(r io.Reader) (results []BenchResult, skipped int, err error) {
+	type key struct {
+		label string
+		n     int
+	}
+	type acc struct {
+		samples       []float64
+		bytes, allocs float64
+		withMem       bool
+	}
+	accs := make(map[key]*acc)
+	var order []key
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		m := benchLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		label, n, ok := splitBenchName(m[1])
+		if !ok {
+			skipped++
+			continue
+		}
+		nsOp, err := strconv.ParseFloat(m[3], 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%q: %v", line, err)
+		}
+		k := key{label, n}
+		a := accs[k]
+		if a == nil {
+			a = &acc{}
+			accs[k] = a
+			order = append(order, k)
+		}
+		a.samples = append(a.samples, nsOp/1e6)
+		for _, mm := range benchMem.FindAllStringSubmatch(m[4], -1) {
+			v, _ := strconv.ParseFloat(mm[1], 64)
+			if mm[2] == "B" {
+				a.bytes += v
+			} else {
+				a.allocs += v
+			}
+			a.withMem = true
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		if order[i].label != order[j].label {
+			return order[i].label < order[j].label
+		}
+		return order[i].n < order[j].n
+	})
+	for _, k := range order {
+		a := accs[k]
+		res := config.Summarize(a.samples)
+		res.N = k.n
+		if a.withMem {
+			runs := float64(len(a.samples))
+			res.Mem = &config.MemResult{AllocBytes: a.bytes / runs, Allocs: a.allocs / runs}
+		}
+		results = append(results, BenchResult{Label: k.label, Result: res})
+	}
+	return results, skipped, nil
+}
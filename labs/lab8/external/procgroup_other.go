@@ -0,0 +1,9 @@
+//go:build !unix
+
+package external
+
+import "os/exec"
+
+// killGroupOnCancel leaves the default cancelation, which kills only the
+// shell; WaitDelay still bounds the wait for the commands it started.
+func killGroupOnCancel(cmd *exec.Cmd) {}
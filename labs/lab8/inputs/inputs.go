@@ -0,0 +1,98 @@
+// Package inputs generates the input arrays for the sorting and searching
+// suite. Every generator is driven by a *rand.Rand, so a seed reproduces the
+// same arrays.
+package inputs
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Generator returns an array of n ints.
+type Generator func(rng *rand.Rand, n int) []int
+
+// Distribution is a named Generator.
+type Distribution struct {
+	Name        string
+	Description string
+	Gen         Generator
+}
+
+// Distributions are the available input shapes, in the order the suite
+// reports them.
+var Distributions = []Distribution{
+	{"sorted", "0, 1, ..., n-1", Sorted},
+	{"reverse", "n-1, ..., 1, 0", Reverse},
+	{"random", "a random permutation of 0..n-1", Random},
+	{"nearly-sorted", "sorted, then n/20 random pairs swapped", NearlySorted},
+	{"many-duplicates", "uniform values in [0, √n)", ManyDuplicates},
+}
+
+// Sorted returns 0..n-1.
+func Sorted(_ *rand.Rand, n int) []int {
+	a := make([]int, n)
+	for i := range a {
+		a[i] = i
+	}
+	return a
+}
+
+// Reverse returns n-1..0.
+func Reverse(_ *rand.Rand, n int) []int {
+	a := make([]int, n)
+	for i := range a {
+		a[i] = n - 1 - i
+	}
+	return a
+}
+
+// Random returns a random permutation of 0..n-1.
+func Random(rng *rand.Rand, n int) []int {
+	return rng.Perm(n)
+}
+
+// NearlySorted returns 0..n-1 with ⌈n/20⌉ random pairs swapped (none for
+// n < 2).
+func NearlySorted(rng *rand.Rand, n int) []int {
+	a := Sorted(rng, n)
+	if n < 2 {
+		return a
+	}
+	for k := 0; k < (n+19)/20; k++ {
+		i, j := rng.Intn(n), rng.Intn(n)
+		a[i], a[j] = a[j], a[i]
+	}
+	return a
+}
+
+// ManyDuplicates returns n values drawn uniformly from [0, ⌈√n⌉), so each
+// value appears about √n times.
+func ManyDuplicates(rng *rand.Rand, n int) []int {
+	k := int(math.Ceil(math.Sqrt(float64(n))))
+	if k < 1 {
+		k = 1
+	}
+	a := make([]int, n)
+	for i := range a {
+		a[i] = rng.Intn(k)
+	}
+	return a
+}
+
+// Lookup returns the distribution with that name.
+func Lookup(name string) (Distribution, error) {
+	for _, d := range Distributions {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+	names := make([]string, len(Distributions))
+	for i, d := range Distributions {
+		names[i] = d.Name
+	}
+	sort.Strings(names)
+	return Distribution{}, fmt.Errorf("unknown distribution %q (available: %s)", name, strings.Join(names, ", "))
+}
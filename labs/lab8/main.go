@@ -1,28 +1,46 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"lab8/config" // <- usa tu module path real
-	"lab8/ex1"
-	"lab8/ex2"
-	"lab8/ex3"
 	"lab8/ex4"
+	_ "lab8/exercises" // registra los ejercicios
+	"lab8/external"
+	"lab8/inputs"
+	"lab8/sorting"
 )
 
 func main() {
 	var (
 		// RUN flags (ya los tienes)
-		exercise int
+		exercise string
+		list     bool
+		all      bool
 		n        int
 		nsRaw    string
 		runs     int
 		out      string
+		warmup   int
+		maxRuns  int
+		relErr   float64
+		budget   time.Duration
+		mem      bool
+		profDir  string
+		timeout  time.Duration
+		onTO     string
 
 		// PLOT flags (nuevos/ajustados)
 		mode            string
@@ -31,60 +49,282 @@ func main() {
 		logx, logy      bool
 		ymin            float64
 		nolines         bool
+		group           bool
+		refs            string
+		format          string
+		yCol            string
 		pSuccess        float64
 		outDir          string
+		trials          int
+		seed            int64
+		algos, dists    string
+		cmdTpl, input   string
+		label           string
+
+		// FIT flags
+		minN    float64
+		overlay bool
 	)
 
 	// -------- Flags (RUN) --------
-	flag.IntVar(&exercise, "exercise", 1, "exercise to run (1|2|3)")
+	flag.StringVar(&exercise, "exercise", "ex1", "registered exercise to run, or whose closed form to check with -mode=ops (see -list)")
+	flag.BoolVar(&list, "list", false, "list the registered exercises and exit")
+	flag.BoolVar(&all, "all", false, "run every registered exercise across -ns")
 	flag.IntVar(&n, "n", 1000, "single input size n")
 	flag.StringVar(&nsRaw, "ns", "", "comma-separated list of n values (e.g. 1,10,100)")
-	flag.IntVar(&runs, "runs", 3, "repetitions for averaging")
-	flag.StringVar(&out, "out", "", "CSV output (defaults to results/ex0X.csv)")
+	flag.IntVar(&runs, "runs", 3, "minimum repetitions for averaging")
+	flag.IntVar(&warmup, "warmup", config.DefaultTimeOptions.Warmup, "unmeasured warm-up runs per n")
+	flag.IntVar(&maxRuns, "maxruns", config.DefaultTimeOptions.MaxRuns, "maximum repetitions when -relerr > 0")
+	flag.Float64Var(&relErr, "relerr", config.DefaultTimeOptions.RelErr, "repeat until the 95% CI is below this fraction of the mean (0 = exactly -runs)")
+	flag.DurationVar(&budget, "budget", config.DefaultTimeOptions.MaxBudget, "stop adding repetitions for an n after this much measured time")
+	flag.BoolVar(&mem, "mem", false, "also record bytes allocated, allocations, GCs and peak heap per n")
+	flag.StringVar(&profDir, "profile", "", "directory for pprof CPU and heap profiles, one pair per n")
+	flag.DurationVar(&timeout, "timeout", 0, "time limit per n, warm-up included (0 = none); late sizes get note=timeout")
+	flag.StringVar(&onTO, "ontimeout", "skip", "after a timeout: skip the remaining sizes of that exercise, or continue with them")
+	flag.StringVar(&out, "out", "", "CSV output (defaults to results/<label>.csv per exercise)")
 
-	flag.StringVar(&mode, "mode", "run", "run | plot")
-	flag.StringVar(&inPlot, "inplot", "", "input CSV (when -mode=plot)")
-	flag.StringVar(&outPlot, "outplot", "plots/out.png", "output PNG (when -mode=plot)")
+	flag.StringVar(&mode, "mode", "run", "run | exec | gobench | plot | fit | ops | gen-linear | mc-linear | suite")
+	flag.StringVar(&inPlot, "inplot", "", "input CSV (when -mode=plot or -mode=fit); comma-separated list for several series (mode=plot); go test -bench output, - for stdin (mode=gobench)")
+	flag.StringVar(&outPlot, "outplot", "plots/out.png", "output image (when -mode=plot); the extension selects PNG or SVG")
 	flag.StringVar(&title, "title", "", "plot title (when -mode=plot)")
 	flag.BoolVar(&logx, "logx", false, "log scale on X (when -mode=plot)")
 	flag.BoolVar(&logy, "logy", false, "log scale on Y (when -mode=plot)")
 	flag.Float64Var(&ymin, "ymin", 0, "minimum Y value (>0) for plotting (when -mode=plot)")
 	flag.BoolVar(&nolines, "nolines", false, "plot points only (no connecting line)")
+	flag.BoolVar(&group, "group", false, "one series per value of the exercise column (when -mode=plot)")
+	flag.StringVar(&refs, "ref", "", "comma-separated reference curves, e.g. \"n,n^2\" (when -mode=plot)")
+	flag.StringVar(&yCol, "ycol", "avg_ms", "CSV column for Y, e.g. alloc_bytes or peak_heap_bytes (when -mode=plot or -mode=fit)")
+	flag.StringVar(&format, "format", "", "png | svg: replaces the extension of -outplot")
 	flag.Float64Var(&pSuccess, "p", 1.0, "success probability for average mixed (0..1) (mode=gen-linear)")
-	flag.StringVar(&outDir, "outdir", "results/ex04", "output directory for linear search CSVs (mode=gen-linear)")
+	flag.StringVar(&outDir, "outdir", "results/ex04", "output directory for linear search CSVs (mode=gen-linear|mc-linear)")
+	flag.IntVar(&trials, "trials", 2000, "random searches per n (mode=mc-linear); inputs per n, default 5 (mode=suite)")
+	flag.Int64Var(&seed, "seed", 1, "RNG seed (mode=mc-linear|suite|exec)")
+	flag.StringVar(&algos, "algos", "", "comma-separated algorithms, default all (mode=suite)")
+	flag.StringVar(&cmdTpl, "cmd", "", "shell command to time, {n} and {input} are replaced (mode=exec)")
+	flag.StringVar(&input, "input", "", "input file per n: a distribution (e.g. random) or words:a,b,... (mode=exec)")
+	flag.StringVar(&label, "label", "exec", "value of the exercise column (mode=exec)")
+	flag.StringVar(&dists, "dists", "", "comma-separated input distributions, default all (mode=suite)")
+	flag.Float64Var(&minN, "minn", 0, "ignore rows with n below this value (when -mode=fit)")
+	flag.BoolVar(&overlay, "overlay", false, "also plot the CSV with the best-fit curve to -outplot (when -mode=fit)")
 	flag.Parse()
 
+	outPlot, err := config.OutputPath(outPlot, format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if list {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "name\tcomplexity\tcsv\tdescription")
+		for _, e := range config.Exercises() {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.Name, e.Complexity, e.DefaultCSV(), e.Description)
+		}
+		tw.Flush()
+		return
+	}
+
 	// ---- en tu manejo de modos, antes del modo "run" ----
 	if mode == "gen-linear" {
-		// parse ns (reuse your existing ns parsing)
-		ns := []int{n}
-		if strings.TrimSpace(nsRaw) != "" {
-			parts := strings.Split(nsRaw, ",")
-			ns = ns[:0]
-			for _, p := range parts {
-				p = strings.TrimSpace(p)
-				if p == "" {
-					continue
+		ns, err := parseNs(nsRaw, n)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := ex4.GenerateCSVs(ns, pSuccess, outDir); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Linear search CSVs written to:", outDir)
+		return
+	}
+
+	// ---------- MC-LINEAR mode ----------
+	if mode == "mc-linear" {
+		ns, err := parseNs(nsRaw, n)
+		if err != nil {
+			log.Fatal(err)
+		}
+		path, err := ex4.GenerateEmpiricalCSV(ns, pSuccess, trials, seed, outDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Monte Carlo linear search CSV written to:", path)
+		if !flagSet("outplot") {
+			outPlot = fmt.Sprintf("plots/ex04_empirical_p%.2f.png", pSuccess)
+		}
+		if strings.TrimSpace(title) == "" {
+			title = fmt.Sprintf("Linear Search — formula vs Monte Carlo (p=%.2f, %d trials)", pSuccess, trials)
+		}
+		if err := ex4.PlotEmpirical(path, outPlot, title, logx, logy); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Plot saved to", outPlot)
+		return
+	}
+
+	// ---------- SUITE mode ----------
+	if mode == "suite" {
+		ns, err := parseNs(nsRaw, n)
+		if err != nil {
+			log.Fatal(err)
+		}
+		algs := sorting.Algorithms
+		if names := splitList(algos); len(names) > 0 {
+			algs = nil
+			for _, name := range names {
+				a, err := sorting.LookupAlgorithm(name)
+				if err != nil {
+					log.Fatal(err)
 				}
-				if strings.ContainsAny(p, "eE") {
-					f, err := strconv.ParseFloat(p, 64)
-					if err != nil {
-						log.Fatal(err)
-					}
-					ns = append(ns, int(f))
-				} else {
-					v, err := strconv.Atoi(p)
-					if err != nil {
-						log.Fatal(err)
-					}
-					ns = append(ns, v)
+				algs = append(algs, a)
+			}
+		}
+		ds := inputs.Distributions
+		if names := splitList(dists); len(names) > 0 {
+			ds = nil
+			for _, name := range names {
+				d, err := inputs.Lookup(name)
+				if err != nil {
+					log.Fatal(err)
 				}
+				ds = append(ds, d)
 			}
 		}
-		if err := ex4.GenerateCSVs(ns, pSuccess, outDir); err != nil {
+		if !flagSet("trials") {
+			trials = 5
+		}
+		if strings.TrimSpace(out) == "" {
+			out = "results/suite.csv"
+		}
+		report := func(r sorting.SuiteRow) {
+			fmt.Printf("%-28s n=%-8d avg=%.6f ms  cmp=%.0f  swaps=%.0f  moves=%.0f\n",
+				r.Label(), r.N, r.AvgMs, r.Comparisons, r.Swaps, r.Moves)
+		}
+		if err := sorting.RunSuite(algs, ds, ns, trials, seed, out, report); err != nil {
 			log.Fatal(err)
 		}
-		fmt.Println("Linear search CSVs written to:", outDir)
+		fmt.Println("Suite CSV written to:", out)
+		return
+	}
+
+	// ---------- GOBENCH mode ----------
+	if mode == "gobench" {
+		var r io.Reader = os.Stdin
+		if strings.TrimSpace(inPlot) == "" {
+			log.Fatal("missing -inplot=<go test -bench output> (- for stdin)")
+		}
+		if inPlot != "-" {
+			f, err := os.Open(inPlot)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer f.Close()
+			r = f
+		}
+		results, skipped, err := external.ParseGoBench(r)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(results) == 0 {
+			log.Fatal("no benchmark results with a size in the name (e.g. BenchmarkSort/n=1000)")
+		}
+		if strings.TrimSpace(out) == "" {
+			out = "results/gobench.csv"
+		}
+		var rows [][]string
+		for _, b := range results {
+			fmt.Printf("[%s] n=%d avg=%.6fms runs=%d\n", b.Label, b.Result.N, b.Result.AvgMs, b.Result.Runs)
+			rows = append(rows, external.Row(b.Result, b.Label))
+		}
+		if err := config.AppendCSV(out, config.CSVHeader, rows); err != nil {
+			log.Fatal(err)
+		}
+		if skipped > 0 {
+			fmt.Printf("%d benchmark lines without a size skipped\n", skipped)
+		}
+		fmt.Println("Go benchmark CSV written to:", out)
+		return
+	}
+
+	// ---------- FIT mode ----------
+	if mode == "fit" {
+		if strings.TrimSpace(inPlot) == "" {
+			log.Fatal("missing -inplot=<csv>")
+		}
+		rows, err := config.LoadCSVColumn(inPlot, yCol)
+		if err != nil {
+			log.Fatal(err)
+		}
+		report, err := config.FitModels(rows, minN)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := config.WriteFitReport(os.Stdout, report); err != nil {
+			log.Fatal(err)
+		}
+		best := report.Best()
+		fmt.Printf("Best fit: O(%s), %s ≈ %.4g·%s\n", best.Model.Name, yCol, best.C, best.Model.Name)
+		if overlay {
+			if strings.TrimSpace(title) == "" {
+				title = "n vs " + yCol + ", best fit O(" + best.Model.Name + ")"
+			}
+			if err := config.PlotCSVWithFit(inPlot, outPlot, title, "n", yCol, logx, logy, ymin, !nolines, best); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println("Plot saved to", outPlot)
+		}
+		return
+	}
+
+	// ---------- OPS mode ----------
+	if mode == "ops" {
+		if strings.TrimSpace(inPlot) == "" {
+			log.Fatal("missing -inplot=<csv>")
+		}
+		e, ok := config.Lookup(exercise)
+		if !ok {
+			log.Fatal(config.UnknownExercise(exercise))
+		}
+		if e.Ops == nil {
+			log.Fatalf("%s has no closed-form operation count", e.Name)
+		}
+		rows, err := config.LoadCSV(inPlot)
+		if err != nil {
+			log.Fatal(err)
+		}
+		// en un CSV de -all solo cuentan las filas de este ejercicio
+		var own []config.PlotRow
+		for _, sr := range config.GroupByLabel(rows) {
+			if sr.Label == e.Label {
+				own = sr.Rows
+			}
+		}
+		if len(own) == 0 {
+			log.Fatalf("%s has no rows with exercise=%s", inPlot, e.Label)
+		}
+		rows = own
+		checks, err := config.CheckOps(rows, e.Ops)
+		if err != nil {
+			log.Fatal(err)
+		}
+		mismatches := 0
+		for _, c := range checks {
+			mark := "ok"
+			if c.Diff() != 0 {
+				mark = fmt.Sprintf("DIFF %+d", c.Diff())
+				mismatches++
+			}
+			fmt.Printf("n=%d counted=%d closed-form=%d %s\n", c.N, c.Counted, c.Expected, mark)
+		}
+		if strings.TrimSpace(title) == "" {
+			title = fmt.Sprintf("%s: counted vs closed-form operations", e.Label)
+		}
+		if err := config.PlotOps(checks, outPlot, title, logx, logy); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Plot saved to", outPlot)
+		if mismatches > 0 {
+			fmt.Fprintf(os.Stderr, "%d of %d sizes differ from the closed form\n", mismatches, len(checks))
+			os.Exit(1)
+		}
 		return
 	}
 
@@ -95,6 +335,26 @@ func main() {
 		}
 		if strings.TrimSpace(title) == "" {
 			title = "n vs time (ms)"
+			if yCol != "avg_ms" {
+				title = "n vs " + yCol
+			}
+		}
+		paths := splitList(inPlot)
+		if len(paths) > 1 || group || refs != "" || yCol != "avg_ms" {
+			series, err := config.LoadSeries(paths, group, yCol)
+			if err != nil {
+				log.Fatal(err)
+			}
+			opts := config.PlotOptions{
+				Title: title, XLabel: "n", YLabel: yCol,
+				LogX: logx, LogY: logy, YMin: ymin, DrawLine: !nolines,
+				Refs: splitList(refs),
+			}
+			if err := config.PlotSeries(series, outPlot, opts); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("Plot with %d series saved to %s\n", len(series), outPlot)
+			return
 		}
 		// drawLine es !nolines
 		if err := config.PlotCSVWithOpts(inPlot, outPlot, title, "n", "avg_ms", logx, logy, ymin, !nolines); err != nil {
@@ -105,80 +365,193 @@ func main() {
 	}
 
 	// ---------- RUN mode ----------
-	// Default CSV per exercise if user didn't pass -out
-	if strings.TrimSpace(out) == "" {
-		switch exercise {
-		case 1:
-			out = "results/ex01.csv"
-		case 2:
-			out = "results/ex02.csv"
-		case 3:
-			out = "results/ex03.csv"
-		default:
-			out = "results/unknown.csv"
-		}
-	}
-
-	// Pick runner
-	var runner config.Runner
-	switch exercise {
-	case 1:
-		runner = ex1.Ex1
-	case 2:
-		runner = ex2.Ex2
-	case 3:
-		runner = ex3.Ex3
-	default:
-		fmt.Fprintln(os.Stderr, "use -exercise=1, -exercise=2, or -exercise=3")
-		os.Exit(2)
-	}
-
-	// Parse ns or fallback to single n
-	ns := []int{n}
-	if strings.TrimSpace(nsRaw) != "" {
-		parts := strings.Split(nsRaw, ",")
-		ns = ns[:0]
-		for _, p := range parts {
-			p = strings.TrimSpace(p)
-			if p == "" {
-				continue
-			}
-			if strings.ContainsAny(p, "eE") {
-				f, err := strconv.ParseFloat(p, 64)
-				if err != nil {
-					log.Fatal(err)
-				}
-				ns = append(ns, int(f))
-			} else {
-				v, err := strconv.Atoi(p)
-				if err != nil {
-					log.Fatal(err)
-				}
-				ns = append(ns, v)
-			}
+	ns, err := parseNs(nsRaw, n)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if onTO != "skip" && onTO != "continue" {
+		log.Fatalf("-ontimeout must be skip or continue, not %q", onTO)
+	}
+	rc := runConfig{
+		opts:      config.TimeOptions{Warmup: warmup, MinRuns: runs, MaxRuns: maxRuns, RelErr: relErr, MaxBudget: budget, Memory: mem},
+		profDir:   profDir,
+		timeout:   timeout,
+		onTimeout: onTO,
+	}
+
+	// Ctrl+C corta el tamaño en curso (queda con note=canceled) y termina;
+	// un segundo Ctrl+C mata el proceso como siempre
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	// ---------- EXEC mode ----------
+	if mode == "exec" {
+		if mem || profDir != "" {
+			// medirían este proceso, no el comando
+			fmt.Fprintln(os.Stderr, "-mem and -profile are ignored with -mode=exec")
+			rc.opts.Memory, rc.profDir = false, ""
+		}
+		if strings.TrimSpace(out) == "" {
+			out = "results/" + label + ".csv"
+		}
+		c := external.Command{Template: cmdTpl, Input: input, Seed: seed}
+		err := runSizes(ctx, label, ns, rc, out, c.Prepare, external.Row)
+		if ctx.Err() != nil {
+			fmt.Fprintln(os.Stderr, "interrupted; the rows measured so far are in", out)
+			os.Exit(130)
+		}
+		if err != nil {
+			log.Fatal(err)
 		}
+		return
 	}
 
-	// Measure and write CSV incrementally
-	exLabel := fmt.Sprintf("ex%02d", exercise)
-	header := []string{"exercise", "n", "avg_ms", "runs", "note"}
+	var exercises []config.Exercise
+	if all {
+		exercises = config.Exercises()
+	} else {
+		e, ok := config.Lookup(exercise)
+		if !ok {
+			fmt.Fprintln(os.Stderr, config.UnknownExercise(exercise))
+			os.Exit(2)
+		}
+		exercises = []config.Exercise{e}
+	}
+	for _, e := range exercises {
+		// -out junta todo en un CSV (separable con -group); si no, uno por ejercicio
+		path := out
+		if strings.TrimSpace(path) == "" {
+			path = e.DefaultCSV()
+		}
+		err := runExercise(ctx, e, ns, rc, path)
+		if ctx.Err() != nil {
+			fmt.Fprintln(os.Stderr, "interrupted; the rows measured so far are in", path)
+			os.Exit(130)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
 
+// runConfig son las opciones del modo run que no cambian por ejercicio.
+type runConfig struct {
+	opts      config.TimeOptions
+	profDir   string        // perfiles pprof por n, si no está vacío
+	timeout   time.Duration // plazo por n; 0 sin plazo
+	onTimeout string        // "skip" o "continue"
+}
+
+// runExercise mide e en cada tamaño y escribe cada fila al CSV apenas la
+// obtiene, también las que terminan por timeout. Con rc.profDir, cada n deja
+// <profDir>/<label>_n<n>.{cpu,heap}.pprof.
+func runExercise(ctx context.Context, e config.Exercise, ns []int, rc runConfig, path string) error {
+	prepare := func(size int) (config.ContextRunner, func(), error) {
+		// con plazo hace falta poder cortar una corrida a la mitad
+		return e.Measured(size, rc.timeout > 0), func() {}, nil
+	}
+	return runSizes(ctx, e.Label, ns, rc, path, prepare, config.Result.Row)
+}
+
+// runSizes es el bucle de runExercise para cualquier cosa medible: prepare
+// devuelve lo que se mide en un tamaño (y done, que lo libera) y row
+// convierte el resultado en la fila del CSV.
+func runSizes(ctx context.Context, label string, ns []int, rc runConfig, path string,
+	prepare func(n int) (r config.ContextRunner, done func(), err error),
+	row func(res config.Result, label string) []string) error {
+
+	opts := rc.opts
 	for idx, size := range ns {
-		res := config.TimeN(runner, size, runs)
-		fmt.Printf("[%s] n=%d avg=%.3fms runs=%d\n", exLabel, res.N, res.AvgMs, res.Runs)
+		runner, done, err := prepare(size)
+		if err != nil {
+			return err
+		}
+		if rc.profDir != "" {
+			opts.ProfilePrefix = filepath.Join(rc.profDir, fmt.Sprintf("%s_n%d", label, size))
+		}
+		sizeCtx, cancel := ctx, context.CancelFunc(func() {})
+		if rc.timeout > 0 {
+			sizeCtx, cancel = context.WithTimeout(ctx, rc.timeout)
+		}
+		res, runErr := config.TimeNContext(sizeCtx, runner, size, opts)
+		cancel()
+		done()
 
-		row := []string{
-			exLabel,
-			strconv.Itoa(res.N),
-			fmt.Sprintf("%.3f", res.AvgMs),
-			strconv.Itoa(res.Runs),
-			"",
+		fmt.Printf("[%s] n=%d avg=%.3fms ±%.3f median=%.3fms runs=%d\n", label, res.N, res.AvgMs, res.CI95Ms, res.MedianMs, res.Runs)
+		if m := res.Mem; m != nil {
+			fmt.Printf("[%s] n=%d alloc=%.0fB allocs=%.0f gc=%.2f peak_heap=%dB\n", label, res.N, m.AllocBytes, m.Allocs, m.NumGC, m.PeakHeapBytes)
 		}
-		if err := config.AppendCSV(out, header, [][]string{row}); err != nil {
-			log.Fatal(err)
+		if res.Note != "" {
+			fmt.Printf("[%s] n=%d note: %s\n", label, res.N, res.Note)
 		}
-		if idx == 0 {
-			header = nil
+
+		// AppendCSV solo escribe el encabezado si el archivo no existe
+		if err := config.AppendCSV(path, config.CSVHeader, [][]string{row(res, label)}); err != nil {
+			return err
+		}
+		if runErr == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !errors.Is(runErr, context.DeadlineExceeded) {
+			return runErr // p. ej. un comando de -mode=exec que falló
+		}
+		if rc.onTimeout == "skip" && idx+1 < len(ns) {
+			fmt.Printf("[%s] timeout at n=%d; skipping %v\n", label, size, ns[idx+1:])
+			return nil
+		}
+	}
+	return nil
+}
+
+// parseNs lee la lista de -ns (acepta notación como 1e6); vacía, usa n.
+func parseNs(raw string, n int) ([]int, error) {
+	if strings.TrimSpace(raw) == "" {
+		return []int{n}, nil
+	}
+	var ns []int
+	for _, p := range splitList(raw) {
+		if strings.ContainsAny(p, "eE") {
+			f, err := strconv.ParseFloat(p, 64)
+			if err != nil {
+				return nil, err
+			}
+			ns = append(ns, int(f))
+		} else {
+			v, err := strconv.Atoi(p)
+			if err != nil {
+				return nil, err
+			}
+			ns = append(ns, v)
+		}
+	}
+	return ns, nil
+}
+
+// flagSet indica si el flag se pasó en la línea de comandos.
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// splitList separa una lista por comas, sin elementos vacíos.
+func splitList(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
 		}
 	}
+	return out
 }
@@ -0,0 +1,214 @@
+// Package sorting holds the instrumented sorting and searching algorithms of
+// the suite. Every algorithm counts its element comparisons and data
+// movement in a Counter.
+package sorting
+
+import (
+	"context"
+	"sort"
+)
+
+// Counter accumulates the work of one call. Swaps counts exchanges of two
+// elements; Moves counts single-element writes, which is how insertion and
+// merge sort move data.
+//
+// If Ctx is set, the sorts check it in their outer loop and return early
+// once it is done, leaving the slice partly sorted; Err then reports why.
+type Counter struct {
+	Comparisons uint64
+	Swaps       uint64
+	Moves       uint64
+
+	Ctx   context.Context
+	err   error
+	ticks uint
+}
+
+// Err returns Ctx.Err() if an algorithm stopped early because of it.
+func (c *Counter) Err() error {
+	return c.err
+}
+
+// stopped is called once per outer-loop iteration and reports whether the
+// algorithm must return. Ctx is only looked at every 64 calls, so checking
+// it costs little next to the work of the loop.
+func (c *Counter) stopped() bool {
+	if c.err != nil {
+		return true
+	}
+	if c.Ctx == nil {
+		return false
+	}
+	c.ticks++
+	if c.ticks&63 == 0 {
+		c.err = c.Ctx.Err()
+	}
+	return c.err != nil
+}
+
+// less compares two elements and counts it.
+func (c *Counter) less(x, y int) bool {
+	c.Comparisons++
+	return x < y
+}
+
+// swap exchanges a[i] and a[j] and counts it.
+func (c *Counter) swap(a []int, i, j int) {
+	c.Swaps++
+	a[i], a[j] = a[j], a[i]
+}
+
+// Insertion sorts a in place. Best case (sorted) n-1 comparisons; worst case
+// (reverse) n(n-1)/2.
+func Insertion(a []int, c *Counter) {
+	for i := 1; i < len(a) && !c.stopped(); i++ {
+		x := a[i]
+		j := i
+		for j > 0 && c.less(x, a[j-1]) {
+			a[j] = a[j-1]
+			c.Moves++
+			j--
+		}
+		if j != i {
+			a[j] = x
+			c.Moves++
+		}
+	}
+}
+
+// Merge sorts a with top-down merge sort, using a buffer of len(a). Each
+// element copied back into a counts as a move.
+func Merge(a []int, c *Counter) {
+	buf := make([]int, len(a))
+	mergeSort(a, buf, c)
+}
+
+func mergeSort(a, buf []int, c *Counter) {
+	if len(a) < 2 || c.stopped() {
+		return
+	}
+	mid := len(a) / 2
+	mergeSort(a[:mid], buf[:mid], c)
+	mergeSort(a[mid:], buf[mid:], c)
+	if c.err != nil {
+		return
+	}
+
+	copy(buf, a)
+	i, j, k := 0, mid, 0
+	for i < mid && j < len(a) {
+		// take from the right half only when strictly smaller: stable
+		if c.less(buf[j], buf[i]) {
+			a[k] = buf[j]
+			j++
+		} else {
+			a[k] = buf[i]
+			i++
+		}
+		k++
+		c.Moves++
+	}
+	for ; i < mid; i, k = i+1, k+1 {
+		a[k] = buf[i]
+		c.Moves++
+	}
+	for ; j < len(a); j, k = j+1, k+1 {
+		a[k] = buf[j]
+		c.Moves++
+	}
+}
+
+// Quick sorts a with quicksort, Lomuto partition and the last element as
+// pivot. That pivot makes sorted and reverse inputs the O(n²) worst case,
+// which is the point of comparing distributions. Elements equal to the
+// pivot all stay on one side, so a run of m equal values costs O(m²):
+// many-duplicates, with about √n copies of each of √n values, is O(n√n).
+// It recurses on the smaller part only, so the stack stays O(log n).
+func Quick(a []int, c *Counter) {
+	for len(a) > 1 && !c.stopped() {
+		p := partition(a, c)
+		if p < len(a)-1-p {
+			Quick(a[:p], c)
+			a = a[p+1:]
+		} else {
+			Quick(a[p+1:], c)
+			a = a[:p]
+		}
+	}
+}
+
+// partition places the pivot a[len(a)-1] at its final index and returns it.
+func partition(a []int, c *Counter) int {
+	hi := len(a) - 1
+	pivot := a[hi]
+	i := 0
+	for j := 0; j < hi; j++ {
+		if c.less(a[j], pivot) {
+			if i != j {
+				c.swap(a, i, j)
+			}
+			i++
+		}
+	}
+	if i != hi {
+		c.swap(a, i, hi)
+	}
+	return i
+}
+
+// Heap sorts a with heapsort: O(n log n) on every input.
+func Heap(a []int, c *Counter) {
+	n := len(a)
+	for i := n/2 - 1; i >= 0 && !c.stopped(); i-- {
+		siftDown(a, i, n, c)
+	}
+	for end := n - 1; end > 0 && !c.stopped(); end-- {
+		c.swap(a, 0, end)
+		siftDown(a, 0, end, c)
+	}
+}
+
+// siftDown restores the max-heap property of a[:n] below i.
+func siftDown(a []int, i, n int, c *Counter) {
+	for {
+		child := 2*i + 1
+		if child >= n {
+			return
+		}
+		if child+1 < n && c.less(a[child], a[child+1]) {
+			child++
+		}
+		if !c.less(a[i], a[child]) {
+			return
+		}
+		c.swap(a, i, child)
+		i = child
+	}
+}
+
+// BinarySearch returns the first index of x in the sorted slice a, or -1.
+// It halves the range with one comparison per step, about log₂ n in total
+// whatever the target, plus one to check the final position.
+func BinarySearch(a []int, x int, c *Counter) int {
+	lo, hi := 0, len(a)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if c.less(a[mid], x) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo < len(a) {
+		c.Comparisons++
+		if a[lo] == x {
+			return lo
+		}
+	}
+	return -1
+}
+
+// IsSorted reports whether a is in non-decreasing order, without counting.
+func IsSorted(a []int) bool {
+	return sort.IntsAreSorted(a)
+}
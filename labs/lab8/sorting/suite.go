@@ -0,0 +1,192 @@
+package sorting
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"lab8/config"
+	"lab8/inputs"
+)
+
+// Algorithm is one entry of the suite. Run sorts (or searches) the prepared
+// input and reports its work in c; rng picks search targets.
+type Algorithm struct {
+	Name       string
+	Complexity string // best / average / worst
+	Run        func(a []int, rng *rand.Rand, c *Counter)
+}
+
+// Algorithms are the members of the suite.
+var Algorithms = []Algorithm{
+	{"insertion", "O(n) / O(n^2) / O(n^2)", func(a []int, _ *rand.Rand, c *Counter) { Insertion(a, c) }},
+	{"merge", "O(n log n) / O(n log n) / O(n log n)", func(a []int, _ *rand.Rand, c *Counter) { Merge(a, c) }},
+	{"quick", "O(n log n) / O(n log n) / O(n^2)", func(a []int, _ *rand.Rand, c *Counter) { Quick(a, c) }},
+	{"heap", "O(n log n) / O(n log n) / O(n log n)", func(a []int, _ *rand.Rand, c *Counter) { Heap(a, c) }},
+	{"binary-search", "O(1) / O(log n) / O(log n)", searchRandomElement},
+}
+
+// searchRandomElement looks for an element of a, chosen with rng, with
+// BinarySearch. a is the generated input already sorted, since binary search
+// needs it; that sort is not counted.
+func searchRandomElement(a []int, rng *rand.Rand, c *Counter) {
+	if len(a) == 0 {
+		BinarySearch(a, 0, c)
+		return
+	}
+	BinarySearch(a, a[rng.Intn(len(a))], c)
+}
+
+// LookupAlgorithm returns the algorithm with that name.
+func LookupAlgorithm(name string) (Algorithm, error) {
+	for _, a := range Algorithms {
+		if a.Name == name {
+			return a, nil
+		}
+	}
+	return Algorithm{}, fmt.Errorf("unknown algorithm %q", name)
+}
+
+// SetupSeed is the seed of the inputs built for the run mode.
+const SetupSeed = 1
+
+// prepare generates the input of alg on dist for n, sorting it first for
+// binary search.
+func prepare(alg Algorithm, dist inputs.Distribution, rng *rand.Rand, n int) []int {
+	a := dist.Gen(rng, n)
+	if alg.Name == "binary-search" {
+		var ignored Counter
+		Merge(a, &ignored)
+	}
+	return a
+}
+
+// every algorithm × distribution pair is a registered exercise, e.g.
+// "quick/sorted", so the run mode can time it with -exercise
+func init() {
+	for _, alg := range Algorithms {
+		for _, dist := range inputs.Distributions {
+			alg, dist := alg, dist
+			setupCtx := func(n int) config.ContextRunner {
+				rng := rand.New(rand.NewSource(SetupSeed))
+				input := prepare(alg, dist, rng, n)
+				work := make([]int, n)
+				return func(ctx context.Context, _ int) (uint64, error) {
+					copy(work, input) // each run sorts a fresh copy
+					c := Counter{Ctx: ctx}
+					alg.Run(work, rng, &c)
+					return c.Comparisons, c.Err()
+				}
+			}
+			setup := func(n int) config.Runner {
+				r := setupCtx(n)
+				return func(n int) uint64 {
+					v, _ := r(context.Background(), n)
+					return v
+				}
+			}
+			config.Register(config.Exercise{
+				Name:        alg.Name + "/" + dist.Name,
+				Label:       alg.Name + "_" + dist.Name,
+				Description: alg.Name + " on " + dist.Description,
+				Complexity:  alg.Complexity,
+				Runner:      func(n int) uint64 { return setup(n)(n) },
+				Setup:       setup,
+				SetupCtx:    setupCtx,
+			})
+		}
+	}
+}
+
+// SuiteHeader is the CSV schema of RunSuite: the usual first five columns,
+// with avg_ms the mean time of one call (input generation and copying
+// excluded) and runs the number of trials, followed by the mean counts.
+var SuiteHeader = []string{"exercise", "n", "avg_ms", "runs", "note",
+	"comparisons", "swaps", "moves"}
+
+// SuiteRow is the averaged result of one algorithm, distribution and n.
+type SuiteRow struct {
+	Algorithm, Distribution string
+	N, Trials               int
+	AvgMs                   float64
+	Comparisons             float64
+	Swaps                   float64
+	Moves                   float64
+}
+
+// Label is the value of the exercise column, e.g. "quick_sorted", the same
+// the run mode writes for the registered exercise "quick/sorted".
+func (r SuiteRow) Label() string {
+	return r.Algorithm + "_" + r.Distribution
+}
+
+// Row converts r to a SuiteHeader row.
+func (r SuiteRow) Row() []string {
+	return []string{
+		r.Label(),
+		strconv.Itoa(r.N),
+		fmt.Sprintf("%.6f", r.AvgMs),
+		strconv.Itoa(r.Trials),
+		"",
+		fmt.Sprintf("%.1f", r.Comparisons),
+		fmt.Sprintf("%.1f", r.Swaps),
+		fmt.Sprintf("%.1f", r.Moves),
+	}
+}
+
+// Measure runs alg on trials inputs of size n from dist. Trial t uses the
+// seed seed+t, so every algorithm sees the same arrays. It fails if a sort
+// leaves its input unsorted.
+func Measure(alg Algorithm, dist inputs.Distribution, n, trials int, seed int64) (SuiteRow, error) {
+	if trials < 1 {
+		trials = 1
+	}
+	row := SuiteRow{Algorithm: alg.Name, Distribution: dist.Name, N: n, Trials: trials}
+	var total time.Duration
+	for t := 0; t < trials; t++ {
+		rng := rand.New(rand.NewSource(seed + int64(t)))
+		a := prepare(alg, dist, rng, n)
+		var c Counter
+		start := time.Now()
+		alg.Run(a, rng, &c)
+		total += time.Since(start)
+		if !IsSorted(a) {
+			return row, fmt.Errorf("%s left %s input of size %d unsorted", alg.Name, dist.Name, n)
+		}
+		row.Comparisons += float64(c.Comparisons)
+		row.Swaps += float64(c.Swaps)
+		row.Moves += float64(c.Moves)
+	}
+	k := float64(trials)
+	row.AvgMs = float64(total.Nanoseconds()) / 1e6 / k
+	row.Comparisons /= k
+	row.Swaps /= k
+	row.Moves /= k
+	return row, nil
+}
+
+// RunSuite measures every pair of algs and dists at each n and appends the
+// rows to out as they are obtained. report, if not nil, is called per row.
+func RunSuite(algs []Algorithm, dists []inputs.Distribution, ns []int, trials int, seed int64,
+	out string, report func(SuiteRow)) error {
+
+	for _, alg := range algs {
+		for _, dist := range dists {
+			for _, n := range ns {
+				row, err := Measure(alg, dist, n, trials, seed)
+				if err != nil {
+					return err
+				}
+				if report != nil {
+					report(row)
+				}
+				if err := config.AppendCSV(out, SuiteHeader, [][]string{row.Row()}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lab8/config"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
+
+func TestSummarize(t *testing.T) {
+	// 1..10 shuffled: mean 5.5, sum of squares 82.5
+	ten := []float64{7, 3, 10, 1, 5, 9, 2, 8, 4, 6}
+	std10 := math.Sqrt(82.5 / 9)
+	forty := make([]float64, 40)
+	for i := range forty {
+		forty[i] = float64(i%2) * 2 // twenty 0s and twenty 2s
+	}
+	std40 := math.Sqrt(40.0 / 39)
+
+	cases := []struct {
+		name    string
+		samples []float64
+		want    config.Result
+	}{
+		{"one", []float64{4}, config.Result{AvgMs: 4, Runs: 1, MinMs: 4, MedianMs: 4, P95Ms: 4}},
+		{"two", []float64{3, 1}, config.Result{AvgMs: 2, Runs: 2, MinMs: 1, MedianMs: 2, P95Ms: 2.9,
+			StdMs: math.Sqrt2, CI95Ms: 12.706}}, // t(1)·√2/√2,
+		{"ten", ten, config.Result{AvgMs: 5.5, Runs: 10, MinMs: 1, MedianMs: 5.5, P95Ms: 9.55,
+			StdMs: std10, CI95Ms: 2.262 * std10 / math.Sqrt(10)}},
+		// past 30 degrees of freedom the t quantile is the normal 1.96
+		{"forty", forty, config.Result{AvgMs: 1, Runs: 40, MinMs: 0, MedianMs: 1, P95Ms: 2,
+			StdMs: std40, CI95Ms: 1.96 * std40 / math.Sqrt(40)}},
+	}
+	for _, c := range cases {
+		got := config.Summarize(c.samples)
+		w := c.want
+		if got.Runs != w.Runs || !near(got.AvgMs, w.AvgMs) || !near(got.MinMs, w.MinMs) ||
+			!near(got.MedianMs, w.MedianMs) || !near(got.P95Ms, w.P95Ms) ||
+			!near(got.StdMs, w.StdMs) || !near(got.CI95Ms, w.CI95Ms) {
+			t.Errorf("%s: Summarize = %+v, want %+v", c.name, got, w)
+		}
+	}
+	if got := config.Summarize(nil); got.Runs != 0 {
+		t.Errorf("Summarize(nil) = %+v", got)
+	}
+	if ten[0] != 7 {
+		t.Error("Summarize sorted its argument in place")
+	}
+}
+
+// A CSV written before the statistics columns existed keeps its 5-column
+// header; rows appended later have all of CSVHeader. LoadCSV must read both.
+func TestLoadCSVMixedColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.csv")
+	old := "exercise,n,avg_ms,runs,note\nex01,10,1.500000,5,\nex01,20,6.000000,5,\n"
+	if err := os.WriteFile(path, []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res := config.Summarize([]float64{20, 24})
+	res.N, res.Ops = 40, 1600
+	timedOut := config.Result{N: 80, Note: "timeout"}
+	if err := config.AppendCSV(path, config.CSVHeader, [][]string{res.Row("ex01"), timedOut.Row("ex01")}); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := config.LoadCSV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []config.PlotRow{
+		{Label: "ex01", N: 10, AvgMs: 1.5},
+		{Label: "ex01", N: 20, AvgMs: 6},
+		{Label: "ex01", N: 40, AvgMs: 22}, // ci95_ms and ops are not in the old header
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("LoadCSV = %+v, want %+v", rows, want)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+
+	// with the new header the extra columns are read by name
+	path = filepath.Join(t.TempDir(), "new.csv")
+	if err := config.AppendCSV(path, config.CSVHeader, [][]string{res.Row("ex01")}); err != nil {
+		t.Fatal(err)
+	}
+	rows, err = config.LoadCSV(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || !rows[0].HasOps || rows[0].Ops != 1600 || math.Abs(rows[0].CI95-res.CI95Ms) > 1e-6 {
+		t.Errorf("LoadCSV = %+v, want ops 1600 and ci95 %g", rows, res.CI95Ms)
+	}
+}
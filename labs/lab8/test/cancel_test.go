@@ -0,0 +1,106 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"lab8/config"
+	_ "lab8/exercises"
+	"lab8/inputs"
+	"lab8/sorting"
+)
+
+// A sorting exercise measured with a deadline must stop the run in
+// progress: these O(n²) cases take seconds on 200000 values.
+func TestSortingExercisesStopOnTimeout(t *testing.T) {
+	const n = 200000
+	for _, name := range []string{"insertion/reverse", "insertion/random", "quick/sorted", "quick/reverse"} {
+		e, ok := config.Lookup(name)
+		if !ok {
+			t.Fatalf("%s is not registered", name)
+		}
+		r := e.Measured(n, true)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		start := time.Now()
+		_, err := r(ctx, n)
+		elapsed := time.Since(start)
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("%s: err = %v, want a deadline error", name, err)
+		}
+		if errors.Is(err, config.ErrAbandoned) || elapsed > time.Second {
+			t.Errorf("%s: returned after %v, err %v: the run was not interrupted", name, elapsed, err)
+		}
+	}
+}
+
+func TestCounterCtx(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	rng := newRand()
+	for _, alg := range sorting.Algorithms[:4] {
+		a := inputs.Reverse(rng, 10000)
+		c := sorting.Counter{Ctx: canceled}
+		alg.Run(a, rng, &c)
+		if !errors.Is(c.Err(), context.Canceled) {
+			t.Errorf("%s: Err() = %v, want context.Canceled", alg.Name, c.Err())
+		}
+
+		// without Ctx, or with a live one, the sort completes
+		a = inputs.Reverse(rng, 10000)
+		c = sorting.Counter{Ctx: context.Background()}
+		alg.Run(a, rng, &c)
+		if c.Err() != nil || !sorting.IsSorted(a) {
+			t.Errorf("%s: Err() = %v, sorted %v", alg.Name, c.Err(), sorting.IsSorted(a))
+		}
+	}
+}
+
+func newRand() *rand.Rand {
+	return rand.New(rand.NewSource(1))
+}
+
+// A runner without a context-aware variant is abandoned; its row says so.
+func TestAbandonedRunNote(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	slow := config.Runner(func(n int) uint64 { <-release; return 0 })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	res, err := config.TimeNContext(ctx, slow.AbandonOnCancel(), 1, config.TimeOptions{MinRuns: 1})
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, config.ErrAbandoned) {
+		t.Errorf("err = %v, want a deadline error wrapping ErrAbandoned", err)
+	}
+	if res.Note != "timeout; abandoned run" {
+		t.Errorf("note = %q, want %q", res.Note, "timeout; abandoned run")
+	}
+}
+
+// A deadline that expires during the extra -mem run interrupts the row like
+// any other run: no truncated peak_heap_bytes, and the error is returned.
+func TestMemoryPassStopsOnTimeout(t *testing.T) {
+	calls := 0
+	r := config.ContextRunner(func(ctx context.Context, n int) (uint64, error) {
+		calls++
+		if calls == 1 {
+			return 1, nil // the timed run finishes
+		}
+		<-ctx.Done() // the peak-heap run does not
+		return 0, ctx.Err()
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	res, err := config.TimeNContext(ctx, r, 1, config.TimeOptions{MinRuns: 1, Memory: true})
+	if calls != 2 {
+		t.Fatalf("runner called %d times, want 2", calls)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want a deadline error", err)
+	}
+	if res.Note != "timeout" || res.Mem != nil {
+		t.Errorf("note = %q, mem = %+v, want a timeout note and no memory columns", res.Note, res.Mem)
+	}
+}
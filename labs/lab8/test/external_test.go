@@ -0,0 +1,224 @@
+package test
+
+import (
+	"context"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"lab8/external"
+)
+
+// Output of go test -bench . -benchmem -count 2 (GOMAXPROCS 1), followed by
+// go test -bench 'Sort|SHA' -benchmem -cpu 4.
+const goBenchOutput = `goos: linux
+goarch: amd64
+pkg: bb
+cpu: Intel(R) Xeon(R) Processor
+BenchmarkSort/n=100         	    2000	       845.5 ns/op	     896 B/op	       1 allocs/op
+BenchmarkSort/n=100         	    2000	       525.0 ns/op	     896 B/op	       1 allocs/op
+BenchmarkSort/n=1000        	    2000	      5261 ns/op	    8192 B/op	       1 allocs/op
+BenchmarkSort/n=1000        	    2000	      5167 ns/op	    8192 B/op	       1 allocs/op
+BenchmarkFib/10             	    2000	       409.3 ns/op	       0 B/op	       0 allocs/op
+BenchmarkFib/10             	    2000	       402.6 ns/op	       0 B/op	       0 allocs/op
+BenchmarkFib/20             	    2000	     45542 ns/op	       0 B/op	       0 allocs/op
+BenchmarkFib/20             	    2000	     52698 ns/op	       0 B/op	       0 allocs/op
+BenchmarkSHA256             	    2000	       977.9 ns/op	       0 B/op	       0 allocs/op
+BenchmarkSHA256             	    2000	       970.1 ns/op	       0 B/op	       0 allocs/op
+BenchmarkMap                	    2000	        25.56 ns/op	       0 B/op	       0 allocs/op
+BenchmarkMap                	    2000	        25.96 ns/op	       0 B/op	       0 allocs/op
+PASS
+ok  	bb	0.239s
+BenchmarkSort/n=100-4         	    2000	       915.1 ns/op	     896 B/op	       1 allocs/op
+BenchmarkSort/n=1000-4        	    2000	      5297 ns/op	    8194 B/op	       1 allocs/op
+BenchmarkSHA256-4             	    2000	       914.9 ns/op	       0 B/op	       0 allocs/op
+PASS
+`
+
+func TestParseGoBench(t *testing.T) {
+	results, skipped, err := external.ParseGoBench(strings.NewReader(goBenchOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// BenchmarkSHA256 (3 lines) and BenchmarkMap (2) have no size
+	if skipped != 5 {
+		t.Errorf("skipped = %d, want 5", skipped)
+	}
+	want := []struct {
+		label  string
+		n      int
+		avgMs  float64
+		runs   int
+		bytes  float64
+		allocs float64
+	}{
+		{"BenchmarkFib", 10, (409.3 + 402.6) / 2 / 1e6, 2, 0, 0},
+		{"BenchmarkFib", 20, (45542 + 52698) / 2 / 1e6, 2, 0, 0},
+		{"BenchmarkSort", 100, (845.5 + 525.0 + 915.1) / 3 / 1e6, 3, 896, 1},
+		{"BenchmarkSort", 1000, (5261 + 5167 + 5297) / 3.0 / 1e6, 3, (8192 + 8192 + 8194) / 3.0, 1},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(results), len(want), results)
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Label != w.label || r.Result.N != w.n || r.Result.Runs != w.runs ||
+			math.Abs(r.Result.AvgMs-w.avgMs) > 1e-12 {
+			t.Errorf("result %d = %s n=%d avg=%g runs=%d, want %s n=%d avg=%g runs=%d",
+				i, r.Label, r.Result.N, r.Result.AvgMs, r.Result.Runs, w.label, w.n, w.avgMs, w.runs)
+		}
+		if m := r.Result.Mem; m == nil || math.Abs(m.AllocBytes-w.bytes) > 1e-9 || m.Allocs != w.allocs {
+			t.Errorf("result %d (%s n=%d) Mem = %+v, want %g B and %g allocs", i, w.label, w.n, m, w.bytes, w.allocs)
+		}
+	}
+}
+
+func TestParseGoBenchNames(t *testing.T) {
+	cases := []struct {
+		name  string
+		label string
+		n     int // 0: skipped
+	}{
+		{"BenchmarkSort/n=1000-8", "BenchmarkSort", 1000},
+		{"BenchmarkSort/size=64", "BenchmarkSort", 64},
+		{"BenchmarkFib/20-16", "BenchmarkFib", 20},
+		{"BenchmarkFib/20", "BenchmarkFib", 20},
+		{"BenchmarkParse/json/n=10-4", "BenchmarkParse/json", 10},
+		{"BenchmarkSHA256-8", "", 0},
+		{"BenchmarkSHA256", "", 0},
+		{"BenchmarkFib20", "", 0},
+		{"BenchmarkSort_1000", "", 0},
+		{"BenchmarkSort/n=1000/parallel-8", "", 0},
+		{"BenchmarkSort/large-8", "", 0},
+	}
+	for _, c := range cases {
+		line := c.name + "   1000   1500 ns/op\n"
+		results, skipped, err := external.ParseGoBench(strings.NewReader(line))
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if c.n == 0 {
+			if skipped != 1 || len(results) != 0 {
+				t.Errorf("%s: got %+v, skipped %d, want it skipped", c.name, results, skipped)
+			}
+			continue
+		}
+		if len(results) != 1 || results[0].Label != c.label || results[0].Result.N != c.n {
+			t.Errorf("%s: got %+v, want %s n=%d", c.name, results, c.label, c.n)
+		} else if results[0].Result.Mem != nil {
+			t.Errorf("%s: Mem = %+v without -benchmem", c.name, results[0].Result.Mem)
+		}
+	}
+}
+
+func TestWriteInput(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		spec string
+		n    int
+		want string
+	}{
+		{"sorted", 5, "5\n0 1 2 3 4\n"},
+		{"reverse", 3, "3\n2 1 0\n"},
+		{"sorted", 0, "0\n\n"},
+		{"words:a,b", 5, "a b a b a\n"},
+		{"words:id", 1, "id\n"},
+		{"words:x", 0, "\n"},
+	}
+	for i, c := range cases {
+		path := filepath.Join(dir, "in"+string(rune('0'+i)))
+		if err := external.WriteInput(path, c.spec, c.n, 1); err != nil {
+			t.Fatalf("WriteInput(%s, %d): %v", c.spec, c.n, err)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != c.want {
+			t.Errorf("WriteInput(%s, %d) wrote %q, want %q", c.spec, c.n, got, c.want)
+		}
+	}
+
+	// the random distributions are reproducible from the seed
+	read := func(seed int64) string {
+		path := filepath.Join(dir, "random")
+		if err := external.WriteInput(path, "random", 20, seed); err != nil {
+			t.Fatal(err)
+		}
+		b, _ := os.ReadFile(path)
+		return string(b)
+	}
+	if a, b := read(7), read(7); a != b || !strings.HasPrefix(a, "20\n") {
+		t.Errorf("random with the same seed: %q and %q", a, b)
+	}
+	for _, bad := range []string{"words:", "shuffled"} {
+		if err := external.WriteInput(filepath.Join(dir, "bad"), bad, 3, 1); err == nil {
+			t.Errorf("WriteInput(%s) succeeded", bad)
+		}
+	}
+}
+
+func TestCommandShellSyntax(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh")
+	}
+	dir := t.TempDir()
+	cases := []string{
+		"cd " + dir + " && test -f input_n3.txt || test -d .", // builtin first
+		"VAR=1 sh -c 'test \"$VAR\" = 1'",                     // assignment before a command
+		"read n && test \"$n\" = 3",                           // stdin is the input
+		"test \"$(head -1 {input})\" = {n} | cat",             // pipe, {input} and {n}
+	}
+	for _, tmpl := range cases {
+		c := external.Command{Template: tmpl, Input: "sorted", Seed: 1}
+		run, done, err := c.Prepare(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := run(context.Background(), 3); err != nil {
+			t.Errorf("%s: %v", tmpl, err)
+		}
+		done()
+	}
+	run, done, err := external.Command{Template: "echo boom >&2; exit 3"}.Prepare(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+	if _, err := run(context.Background(), 1); err == nil || !strings.HasSuffix(err.Error(), ": boom") {
+		t.Errorf("failing command: err = %v, want it to end with the last stderr line", err)
+	}
+}
+
+// Canceling a compound command kills everything the shell started: the
+// background job would create marker after 500ms if it survived.
+func TestCommandCancelKillsGroup(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh")
+	}
+	marker := filepath.Join(t.TempDir(), "marker")
+	c := external.Command{Template: "(sleep 0.5; touch " + marker + ") & wait"}
+	run, done, err := c.Prepare(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := run(ctx, 1); err != context.DeadlineExceeded {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("run returned after %v: the background job kept the pipes open", elapsed)
+	}
+	time.Sleep(time.Second)
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("the background job survived the cancel")
+	}
+}
@@ -0,0 +1,74 @@
+package test
+
+import (
+	"math"
+	"testing"
+
+	"lab8/config"
+)
+
+func TestFitModels(t *testing.T) {
+	cases := []struct {
+		name  string
+		ns    []float64
+		f     func(n float64) float64
+		model string
+		c     float64
+		slope float64 // log-log slope of the data; 0 skips the check
+	}{
+		{"3n^2", []float64{100, 200, 400, 800, 1600, 3200}, func(n float64) float64 { return 3 * n * n }, "n^2", 3, 2},
+		{"n/2", []float64{1000, 2000, 4000, 8000, 16000}, func(n float64) float64 { return n / 2 }, "n", 0.5, 1},
+		{"n log n", []float64{1 << 10, 1 << 12, 1 << 14, 1 << 16, 1 << 18}, func(n float64) float64 { return 0.01 * n * math.Log2(n) }, "n log n", 0.01, 0},
+		{"constant", []float64{10, 100, 1000, 10000}, func(n float64) float64 { return 7 }, "1", 7, 0},
+		// 2^1050 does not fit in a float64, C·2^n does: the fit must stay in log space
+		{"2^n", []float64{900, 950, 1000, 1050}, func(n float64) float64 { return math.Exp(math.Log(1e-9) + n*math.Ln2) }, "2^n", 1e-9, 0},
+	}
+	for _, c := range cases {
+		var rows []config.PlotRow
+		for _, n := range c.ns {
+			rows = append(rows, config.PlotRow{Label: c.name, N: n, AvgMs: c.f(n)})
+		}
+		report, err := config.FitModels(rows, 0)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		best := report.Best()
+		if best.Model.Name != c.model {
+			t.Errorf("%s: best model %q, want %q", c.name, best.Model.Name, c.model)
+			continue
+		}
+		if math.Abs(best.C-c.c)/c.c > 1e-6 {
+			t.Errorf("%s: C = %g, want %g", c.name, best.C, c.c)
+		}
+		if best.RMSE > 1e-9 || math.Abs(best.R2-1) > 1e-9 {
+			t.Errorf("%s: RMSE = %g, R2 = %g on exact data", c.name, best.RMSE, best.R2)
+		}
+		if c.slope != 0 && (math.Abs(report.Slope-c.slope) > 1e-9 || math.Abs(best.Slope-c.slope) > 1e-9) {
+			t.Errorf("%s: slope = %g (model %g), want %g", c.name, report.Slope, best.Slope, c.slope)
+		}
+		last := c.ns[len(c.ns)-1]
+		if got, want := best.Predict(last), c.f(last); math.IsInf(got, 0) || math.Abs(got-want)/want > 1e-6 {
+			t.Errorf("%s: Predict(%g) = %g, want %g", c.name, last, got, want)
+		}
+	}
+}
+
+func TestFitModelsSkipsRows(t *testing.T) {
+	rows := []config.PlotRow{
+		{N: 1, AvgMs: 5},    // n < 2
+		{N: 10, AvgMs: 0},   // no time
+		{N: 50, AvgMs: 100}, // below minN
+		{N: 100, AvgMs: 300},
+		{N: 200, AvgMs: 1200},
+	}
+	report, err := config.FitModels(rows, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Points != 2 {
+		t.Errorf("Points = %d, want 2", report.Points)
+	}
+	if _, err := config.FitModels(rows[:4], 100); err == nil {
+		t.Error("FitModels with one usable row succeeded")
+	}
+}
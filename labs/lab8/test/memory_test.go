@@ -0,0 +1,76 @@
+package test
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"lab8/config"
+)
+
+// retained keeps the last buffer alive so the allocation escapes and stays
+// on the heap while peakHeap samples it.
+var retained []byte
+
+func TestMemoryColumns(t *testing.T) {
+	const k = 1 << 20
+	alloc := config.Runner(func(n int) uint64 {
+		retained = make([]byte, k)
+		return uint64(len(retained))
+	})
+	opts := config.TimeOptions{Warmup: 1, MinRuns: 5, MaxRuns: 5, Memory: true}
+	res := config.TimeNWithOpts(alloc, 1, opts)
+	m := res.Mem
+	if m == nil {
+		t.Fatal("Mem is nil with Memory set")
+	}
+	// one k-byte object per run, plus at most a page of runtime noise
+	if m.AllocBytes < k || m.AllocBytes > k+4096 {
+		t.Errorf("alloc_bytes = %.0f, want about %d", m.AllocBytes, k)
+	}
+	if m.Allocs < 1 || m.Allocs > 4 {
+		t.Errorf("allocs = %.1f, want about 1", m.Allocs)
+	}
+	if m.NumGC < 0 || m.NumGC > 1 {
+		t.Errorf("num_gc = %.3f, want between 0 and 1", m.NumGC)
+	}
+	if m.PeakHeapBytes < k || m.PeakHeapBytes > 2*k {
+		t.Errorf("peak_heap_bytes = %d, want about %d", m.PeakHeapBytes, k)
+	}
+
+	// a runner that allocates nothing reports nothing
+	none := config.TimeNWithOpts(func(n int) uint64 { return uint64(n) }, 1, opts)
+	if none.Mem == nil || none.Mem.AllocBytes != 0 || none.Mem.Allocs != 0 {
+		t.Errorf("Mem = %+v, want no allocations", none.Mem)
+	}
+
+	// without Memory the columns stay empty
+	opts.Memory = false
+	plain := config.TimeNWithOpts(alloc, 2, opts)
+	if plain.Mem != nil {
+		t.Errorf("Mem = %+v without Memory, want nil", plain.Mem)
+	}
+
+	// the plot and fit modes read the columns back with -ycol
+	path := filepath.Join(t.TempDir(), "mem.csv")
+	if err := config.AppendCSV(path, config.CSVHeader, [][]string{res.Row("alloc"), plain.Row("alloc")}); err != nil {
+		t.Fatal(err)
+	}
+	cols := map[string]float64{
+		"alloc_bytes":     m.AllocBytes,
+		"allocs":          m.Allocs,
+		"num_gc":          m.NumGC,
+		"peak_heap_bytes": float64(m.PeakHeapBytes),
+	}
+	for col, want := range cols {
+		rows, err := config.LoadCSVColumn(path, col)
+		if err != nil {
+			t.Fatalf("LoadCSVColumn(%s): %v", col, err)
+		}
+		// the row measured without Memory has empty cells and is skipped;
+		// Row writes at most three decimals
+		if len(rows) != 1 || rows[0].N != 1 || math.Abs(rows[0].AvgMs-want) > 0.05 {
+			t.Errorf("LoadCSVColumn(%s) = %+v, want one row with n=1 and %g", col, rows, want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"lab8/ex4"
+)
+
+// With a fixed seed the Monte Carlo mean and variance of LinearSearch must
+// fall within their 95% confidence intervals around the closed forms. The
+// interval of the variance uses the normal approximation, 1.96·σ²·√(2/(k−1)).
+func TestSimulateLinearMatchesFormulas(t *testing.T) {
+	const trials = 20000
+	rng := rand.New(rand.NewSource(42))
+	for _, n := range []int{1, 10, 100} {
+		for _, p := range []float64{0, 0.3, 0.5, 1} {
+			e, err := ex4.SimulateLinear(rng, n, trials, p)
+			if err != nil {
+				t.Fatalf("n=%d p=%v: %v", n, p, err)
+			}
+			if e.Formula != ex4.AvgComparisonsMixed(n, p) || e.FormVar != ex4.VarComparisonsMixed(n, p) {
+				t.Errorf("n=%d p=%v: Formula %v, FormVar %v do not match the closed forms", n, p, e.Formula, e.FormVar)
+			}
+			if d := math.Abs(e.Mean - e.Formula); d > e.CI95()+1e-9 {
+				t.Errorf("n=%d p=%v: mean %v, formula %v, |diff| %v > CI95 %v", n, p, e.Mean, e.Formula, d, e.CI95())
+			}
+			ciVar := 1.96 * e.FormVar * math.Sqrt(2/float64(trials-1))
+			if d := math.Abs(e.Variance - e.FormVar); d > ciVar+1e-9 {
+				t.Errorf("n=%d p=%v: variance %v, formula %v, |diff| %v > %v", n, p, e.Variance, e.FormVar, d, ciVar)
+			}
+		}
+	}
+}
+
+func TestSimulateLinearRejectsBadP(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, p := range []float64{-0.1, 1.5, math.NaN()} {
+		if _, err := ex4.SimulateLinear(rng, 10, 10, p); err == nil {
+			t.Errorf("SimulateLinear with p=%v succeeded", p)
+		}
+		dir := t.TempDir()
+		if _, err := ex4.GenerateEmpiricalCSV([]int{10}, p, 10, 1, dir); err == nil {
+			t.Errorf("GenerateEmpiricalCSV with p=%v succeeded", p)
+		}
+		if err := ex4.GenerateCSVs([]int{10}, p, dir); err == nil {
+			t.Errorf("GenerateCSVs with p=%v succeeded", p)
+		}
+	}
+}
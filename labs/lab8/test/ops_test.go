@@ -0,0 +1,39 @@
+package test
+
+import (
+	"testing"
+
+	"lab8/config"
+	_ "lab8/exercises"
+)
+
+// opsSizes covers 0 and 1, odd and even sizes, every residue mod 3 and 4,
+// and powers of two with their neighbours (where ⌊log₂ n⌋ changes).
+func opsSizes() []int {
+	var ns []int
+	for n := 0; n <= 70; n++ {
+		ns = append(ns, n)
+	}
+	for p := 128; p <= 4096; p *= 2 {
+		ns = append(ns, p-1, p, p+1)
+	}
+	return ns
+}
+
+func TestOpsMatchRunner(t *testing.T) {
+	checked := 0
+	for _, e := range config.Exercises() {
+		if e.Ops == nil {
+			continue
+		}
+		checked++
+		for _, n := range opsSizes() {
+			if got, want := e.Runner(n), e.Ops(n); got != want {
+				t.Errorf("%s: Runner(%d) = %d, Ops(%d) = %d", e.Name, n, got, n, want)
+			}
+		}
+	}
+	if checked < 3 {
+		t.Errorf("only %d exercises have Ops, want ex1, ex2 and ex3", checked)
+	}
+}
@@ -0,0 +1,215 @@
+package test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lab8/config"
+)
+
+// writeCSVs writes each file's content into dir and returns their paths in
+// the order of names.
+func writeCSVs(t *testing.T, dir string, names []string, files map[string]string) []string {
+	t.Helper()
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(dir, name)
+		if err := os.WriteFile(paths[i], []byte(files[name]), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return paths
+}
+
+func TestLoadSeries(t *testing.T) {
+	const header = "exercise,n,avg_ms,runs,note\n"
+	cases := []struct {
+		name   string
+		files  map[string]string
+		order  []string
+		group  bool
+		labels []string
+		rows   []int
+	}{
+		{
+			name: "one series per file",
+			files: map[string]string{
+				"a.csv": header + "ex01,10,1,5,\nex01,20,2,5,\n",
+				"b.csv": header + "ex02,10,3,5,\n",
+			},
+			order:  []string{"a.csv", "b.csv"},
+			labels: []string{"ex01", "ex02"},
+			rows:   []int{2, 1},
+		},
+		{
+			name: "same label in two files gets the file name",
+			files: map[string]string{
+				"old.csv": header + "ex01,10,1,5,\n",
+				"new.csv": header + "ex01,10,2,5,\n",
+			},
+			order:  []string{"old.csv", "new.csv"},
+			labels: []string{"old.csv: ex01", "new.csv: ex01"},
+			rows:   []int{1, 1},
+		},
+		{
+			name: "empty label falls back to the file name",
+			files: map[string]string{
+				"bubble.csv": header + ",10,1,5,\n,20,4,5,\n",
+			},
+			order:  []string{"bubble.csv"},
+			labels: []string{"bubble"},
+			rows:   []int{2},
+		},
+		{
+			name: "group splits a file by exercise in order of appearance",
+			files: map[string]string{
+				"all.csv": header + "ex02,10,1,5,\nex01,10,2,5,\nex02,20,3,5,\n",
+			},
+			order:  []string{"all.csv"},
+			group:  true,
+			labels: []string{"ex02", "ex01"},
+			rows:   []int{2, 1},
+		},
+		{
+			name: "group with a label repeated across files",
+			files: map[string]string{
+				"a.csv": header + "ex01,10,1,5,\nex02,10,1,5,\n",
+				"b.csv": header + "ex01,10,2,5,\n",
+			},
+			order:  []string{"a.csv", "b.csv"},
+			group:  true,
+			labels: []string{"a.csv: ex01", "ex02", "b.csv: ex01"},
+			rows:   []int{1, 1, 1},
+		},
+		{
+			name: "a file with no plottable rows is skipped",
+			files: map[string]string{
+				"a.csv":       header + "ex01,10,1,5,\n",
+				"timeout.csv": header + "ex02,10,,0,timeout\n",
+			},
+			order:  []string{"timeout.csv", "a.csv"},
+			labels: []string{"ex01"},
+			rows:   []int{1},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			paths := writeCSVs(t, t.TempDir(), c.order, c.files)
+			series, err := config.LoadSeries(paths, c.group, "avg_ms")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var labels []string
+			var rows []int
+			for _, s := range series {
+				labels = append(labels, s.Label)
+				rows = append(rows, len(s.Rows))
+			}
+			if strings.Join(labels, "|") != strings.Join(c.labels, "|") {
+				t.Errorf("labels = %q, want %q", labels, c.labels)
+			}
+			for i := range rows {
+				if i < len(c.rows) && rows[i] != c.rows[i] {
+					t.Errorf("series %d has %d rows, want %d", i, rows[i], c.rows[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLoadSeriesErrors(t *testing.T) {
+	const header = "exercise,n,avg_ms,runs,note\n"
+	dir := t.TempDir()
+	paths := writeCSVs(t, dir, []string{"timeout.csv", "empty.csv"}, map[string]string{
+		"timeout.csv": header + "ex01,10,,0,timeout\n",
+		"empty.csv":   header,
+	})
+	if _, err := config.LoadSeries(paths[:1], false, "avg_ms"); err == nil {
+		t.Error("LoadSeries with no plottable rows succeeded")
+	}
+	if _, err := config.LoadSeries(paths[1:], false, "avg_ms"); err == nil {
+		t.Error("LoadSeries on a header-only file succeeded")
+	}
+	if _, err := config.LoadSeries(paths[:1], false, "alloc_bytes"); err == nil {
+		t.Error("LoadSeries with a missing -ycol column succeeded")
+	}
+	if _, err := config.LoadSeries([]string{filepath.Join(dir, "missing.csv")}, false, "avg_ms"); err == nil {
+		t.Error("LoadSeries on a missing file succeeded")
+	}
+}
+
+func TestOutputPath(t *testing.T) {
+	cases := []struct {
+		path, format, want string
+		err                bool
+	}{
+		{"plots/cmp.png", "", "plots/cmp.png", false},
+		{"plots/cmp.png", "svg", "plots/cmp.svg", false},
+		{"plots/cmp.svg", "png", "plots/cmp.png", false},
+		{"plots/cmp", "svg", "plots/cmp.svg", false},
+		{"plots/cmp.png", "pdf", "", true},
+		{"plots/cmp.png", "SVG", "", true},
+	}
+	for _, c := range cases {
+		got, err := config.OutputPath(c.path, c.format)
+		if (err != nil) != c.err || got != c.want {
+			t.Errorf("OutputPath(%q, %q) = %q, %v, want %q (error %v)", c.path, c.format, got, err, c.want, c.err)
+		}
+	}
+}
+
+func TestPlotSeries(t *testing.T) {
+	quad := config.Series{Label: "ex01", Rows: []config.PlotRow{
+		{Label: "ex01", N: 10, AvgMs: 1}, {Label: "ex01", N: 20, AvgMs: 4}, {Label: "ex01", N: 40, AvgMs: 16},
+	}}
+	lin := config.Series{Label: "ex02", Rows: []config.PlotRow{
+		{Label: "ex02", N: 10, AvgMs: 1}, {Label: "ex02", N: 20, AvgMs: 2}, {Label: "ex02", N: 40, AvgMs: 4},
+	}}
+	flat := config.Series{Label: "ex03", Rows: []config.PlotRow{
+		{Label: "ex03", N: 1, AvgMs: 3}, {Label: "ex03", N: 10, AvgMs: 0},
+	}}
+	cases := []struct {
+		name   string
+		series []config.Series
+		file   string
+		opts   config.PlotOptions
+		err    string // substring of the expected error, "" for success
+		magic  []string
+	}{
+		{"svg", []config.Series{quad, lin}, "cmp.svg", config.PlotOptions{Title: "t"}, "", []string{"<?xml", "<svg"}},
+		{"png", []config.Series{quad, lin}, "cmp.png", config.PlotOptions{}, "", []string{"\x89PNG"}},
+		{"log axes with refs", []config.Series{quad, lin}, "refs.svg",
+			config.PlotOptions{LogX: true, LogY: true, DrawLine: true, Refs: []string{"n", "n^2"}}, "", []string{"<?xml", "<svg"}},
+		{"unknown ref", []config.Series{quad}, "bad.svg", config.PlotOptions{Refs: []string{"n^7"}}, `unknown reference curve "n^7"`, nil},
+		{"no point for refs", []config.Series{flat}, "flat.svg", config.PlotOptions{Refs: []string{"n"}}, "no point with n >= 2", nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "plots", c.file)
+			err := config.PlotSeries(c.series, out, c.opts)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Errorf("PlotSeries = %v, want an error containing %q", err, c.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ok := false
+			for _, m := range c.magic {
+				ok = ok || bytes.HasPrefix(data, []byte(m))
+			}
+			if !ok {
+				t.Errorf("%s starts with %q, want one of %q", c.file, data[:min(len(data), 16)], c.magic)
+			}
+		})
+	}
+}
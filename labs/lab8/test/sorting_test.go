@@ -0,0 +1,162 @@
+package test
+
+import (
+	"math"
+	"math/bits"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"lab8/inputs"
+	"lab8/sorting"
+)
+
+var sortSizes = []int{0, 1, 2, 3, 10, 100, 1000}
+
+// sorts are the members of the suite that sort, without binary search.
+func sorts(t *testing.T) []sorting.Algorithm {
+	var out []sorting.Algorithm
+	for _, name := range []string{"insertion", "merge", "quick", "heap"} {
+		alg, err := sorting.LookupAlgorithm(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, alg)
+	}
+	return out
+}
+
+func TestEveryAlgorithmSortsEveryDistribution(t *testing.T) {
+	for _, alg := range sorts(t) {
+		for _, dist := range inputs.Distributions {
+			for _, n := range sortSizes {
+				rng := rand.New(rand.NewSource(int64(n)))
+				a := dist.Gen(rng, n)
+				want := append([]int(nil), a...)
+				sort.Ints(want)
+				var c sorting.Counter
+				alg.Run(a, rng, &c)
+				if len(a) != n || (n > 0 && !reflect.DeepEqual(a, want)) {
+					t.Errorf("%s on %s, n=%d: got %v, want %v", alg.Name, dist.Name, n, a, want)
+				}
+				if n < 2 && (c.Comparisons != 0 || c.Swaps != 0 || c.Moves != 0) {
+					t.Errorf("%s on %s, n=%d: counted %+v, want no work", alg.Name, dist.Name, n, c)
+				}
+			}
+		}
+	}
+}
+
+func TestExactCounts(t *testing.T) {
+	tri := func(n int) uint64 { return uint64(n * (n - 1) / 2) }
+	cases := []struct {
+		alg, dist string
+		n         int
+		cmp       uint64
+		field     string // extra check: "moves" or "swaps"
+		extra     uint64
+	}{
+		{"insertion", "sorted", 1000, 999, "moves", 0},
+		{"insertion", "reverse", 1000, tri(1000), "moves", tri(1000) + 999},
+		{"quick", "sorted", 1000, tri(1000), "swaps", 0},
+		{"quick", "sorted", 2, 1, "swaps", 0},
+		// merging two sorted halves compares each element of the left one
+		{"merge", "sorted", 1024, 1024 / 2 * 10, "moves", 1024 * 10},
+		{"merge", "reverse", 1024, 1024 / 2 * 10, "moves", 1024 * 10},
+	}
+	for _, c := range cases {
+		alg, err := sorting.LookupAlgorithm(c.alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dist, err := inputs.Lookup(c.dist)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rng := rand.New(rand.NewSource(1))
+		var cnt sorting.Counter
+		alg.Run(dist.Gen(rng, c.n), rng, &cnt)
+		extra := cnt.Moves
+		if c.field == "swaps" {
+			extra = cnt.Swaps
+		}
+		if cnt.Comparisons != c.cmp || extra != c.extra {
+			t.Errorf("%s on %s, n=%d: comparisons %d, %s %d, want %d, %d",
+				c.alg, c.dist, c.n, cnt.Comparisons, c.field, extra, c.cmp, c.extra)
+		}
+	}
+}
+
+func TestBinarySearch(t *testing.T) {
+	a := []int{1, 2, 2, 2, 3, 5, 5, 8}
+	cases := []struct{ x, want int }{
+		{1, 0}, {2, 1}, {3, 4}, {5, 5}, {8, 7}, // first index of each value
+		{0, -1}, {4, -1}, {9, -1},
+	}
+	for _, c := range cases {
+		var cnt sorting.Counter
+		if got := sorting.BinarySearch(a, c.x, &cnt); got != c.want {
+			t.Errorf("BinarySearch(%v, %d) = %d, want %d", a, c.x, got, c.want)
+		}
+		if max := uint64(bits.Len(uint(len(a)))) + 1; cnt.Comparisons > max {
+			t.Errorf("BinarySearch(%d): %d comparisons, want at most %d", c.x, cnt.Comparisons, max)
+		}
+	}
+	var cnt sorting.Counter
+	if got := sorting.BinarySearch(nil, 1, &cnt); got != -1 || cnt.Comparisons != 0 {
+		t.Errorf("BinarySearch(nil) = %d with %d comparisons", got, cnt.Comparisons)
+	}
+	same := []int{7, 7, 7, 7, 7}
+	if got := sorting.BinarySearch(same, 7, &cnt); got != 0 {
+		t.Errorf("BinarySearch(%v, 7) = %d, want 0", same, got)
+	}
+}
+
+func TestDistributions(t *testing.T) {
+	for _, dist := range inputs.Distributions {
+		for _, n := range sortSizes {
+			a := dist.Gen(rand.New(rand.NewSource(3)), n)
+			b := dist.Gen(rand.New(rand.NewSource(3)), n)
+			if len(a) != n || !reflect.DeepEqual(a, b) {
+				t.Errorf("%s, n=%d: len %d, same seed gives the same array: %v", dist.Name, n, len(a), reflect.DeepEqual(a, b))
+			}
+			if dist.Name == "many-duplicates" {
+				k := int(math.Ceil(math.Sqrt(float64(n))))
+				for _, v := range a {
+					if v < 0 || v >= max(k, 1) {
+						t.Errorf("many-duplicates, n=%d: value %d outside [0, %d)", n, v, k)
+					}
+				}
+				continue
+			}
+			// the others are permutations of 0..n-1
+			sorted := append([]int(nil), a...)
+			sort.Ints(sorted)
+			for i, v := range sorted {
+				if v != i {
+					t.Errorf("%s, n=%d: not a permutation of 0..n-1: %v", dist.Name, n, a)
+					break
+				}
+			}
+		}
+	}
+	if got := inputs.Reverse(nil, 4); !reflect.DeepEqual(got, []int{3, 2, 1, 0}) {
+		t.Errorf("Reverse(4) = %v", got)
+	}
+	if _, err := inputs.Lookup("shuffled"); err == nil {
+		t.Error("Lookup(shuffled) succeeded")
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	alg, _ := sorting.LookupAlgorithm("insertion")
+	dist, _ := inputs.Lookup("reverse")
+	row, err := sorting.Measure(alg, dist, 50, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.Label() != "insertion_reverse" || row.Trials != 3 || row.Comparisons != 50*49/2 {
+		t.Errorf("Measure = %+v", row)
+	}
+}